@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+)
+
+// ErrIncompleteClearsign is returned when a clearsigned document ends before
+// its armored signature does.
+var ErrIncompleteClearsign = errors.New("incomplete clearsigned input")
+
+const (
+	clearsignBegin     = "-----BEGIN PGP SIGNED MESSAGE-----"
+	clearsignSignature = "-----BEGIN PGP SIGNATURE-----"
+)
+
+// clearsignHashNames maps the values of the Hash armor header onto hash
+// algorithms. It is the list go-crypto's clearsign package accepts.
+var clearsignHashNames = map[string]crypto.Hash{
+	"MD5":       crypto.MD5,
+	"SHA1":      crypto.SHA1,
+	"RIPEMD160": crypto.RIPEMD160,
+	"SHA224":    crypto.SHA224,
+	"SHA256":    crypto.SHA256,
+	"SHA384":    crypto.SHA384,
+	"SHA512":    crypto.SHA512,
+	"SHA3-256":  crypto.SHA3_256,
+	"SHA3-512":  crypto.SHA3_512,
+}
+
+// clearsignReader undoes the clearsign framing of a document while it is read,
+// handing the signed text out line by line. The text is digested on the way
+// through, and the signature that trails it is checked when the reader gets
+// there: the read that would otherwise return io.EOF returns the verification
+// error instead, if there is one.
+//
+// Framing follows clearsign.Decode: dash escapes are removed, trailing
+// whitespace is trimmed off every line, and the signed form joins lines with
+// CRLF and leaves the final line ending out.
+type clearsignReader struct {
+	source  *bufio.Reader
+	keyring openpgp.EntityList
+	opts    readerOptions
+	hashes  *messageHashes
+
+	// pending is text that has been unframed but not yet read.
+	pending []byte
+	// firstLine is set until the first line of text has been digested.
+	firstLine bool
+	// headerLines counts the lines ahead of the text.
+	headerLines int
+	// err is returned once pending is drained; io.EOF after a successful
+	// verification.
+	err error
+
+	streamResult
+}
+
+// newClearsignReader consumes the armor header of a clearsigned document and
+// returns a reader over its text.
+func newClearsignReader(source *bufio.Reader, keyring openpgp.EntityList, opts readerOptions) (*clearsignReader, error) {
+	line, err := source.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if strings.TrimRight(line, "\r\n") != clearsignBegin {
+		return nil, errors.New("invalid clearsigned input")
+	}
+
+	var algorithms []crypto.Hash
+
+	headerLines := 1
+	for {
+		line, err := source.ReadString('\n')
+		if err == io.EOF {
+			return nil, ErrIncompleteClearsign
+		} else if err != nil {
+			return nil, err
+		}
+		headerLines++
+
+		// An empty line marks the end of the headers.
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(key) != "Hash" {
+			// Only "Hash" headers are allowed.
+			return nil, errors.New("invalid clearsigned input")
+		}
+
+		for _, name := range strings.Split(value, ",") {
+			algorithm, ok := clearsignHashNames[strings.ToUpper(strings.TrimSpace(name))]
+			if !ok {
+				return nil, errors.New("invalid clearsigned input")
+			}
+
+			algorithms = append(algorithms, algorithm)
+		}
+	}
+
+	if len(algorithms) == 0 {
+		algorithms = candidateHashes
+	}
+
+	return &clearsignReader{
+		source:      source,
+		keyring:     keyring,
+		opts:        opts,
+		hashes:      newMessageHashes(algorithms),
+		firstLine:   true,
+		headerLines: headerLines,
+	}, nil
+}
+
+// clearsignHeaderLines counts the lines of a clearsigned document ahead of its
+// text: the armor header line, the Hash headers and the empty line after them.
+func clearsignHeaderLines(data []byte) int {
+	var lines int
+	for len(data) > 0 {
+		line, rest, _ := bytes.Cut(data, []byte("\n"))
+		data = rest
+		lines++
+
+		if lines > 1 && len(bytes.TrimSpace(line)) == 0 {
+			break
+		}
+	}
+
+	return lines
+}
+
+// Read hands out the unframed text of the document.
+func (r *clearsignReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		r.fill()
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
+
+// fill unframes the next line of the document into pending, or, at the end of
+// the text, checks the signature and records the outcome in err.
+func (r *clearsignReader) fill() {
+	line, err := r.source.ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		r.err = err
+		return
+	}
+
+	line = bytes.TrimRight(line, "\r\n")
+
+	if string(line) == clearsignSignature {
+		r.err = r.verify()
+		return
+	}
+
+	if err == io.EOF {
+		r.err = ErrIncompleteClearsign
+		return
+	}
+
+	// The final line ending isn't included in the digest, so it is only
+	// written once the next line turns up.
+	if r.firstLine {
+		r.firstLine = false
+	} else {
+		_, _ = r.hashes.Write([]byte("\r\n"))
+	}
+
+	// Only spaces and tabs are trimmed off the end of the line, as both
+	// clearsign.Decode and the clearsign signer do; any other whitespace is
+	// part of what was signed.
+	line = bytes.TrimPrefix(line, []byte("- "))
+	line = bytes.TrimRight(line, " \t")
+
+	_, _ = r.hashes.Write(line)
+
+	r.pending = append(line, '\n')
+}
+
+// verify decodes the armored signature that follows the text and checks it
+// against the digests taken so far.
+func (r *clearsignReader) verify() error {
+	// The armor decoder expects to see the header line we just consumed.
+	block, err := armor.Decode(io.MultiReader(strings.NewReader(clearsignSignature+"\n"), r.source))
+	if err != nil {
+		return err
+	}
+
+	reports, err := checkSignatures(r.keyring, block.Body, r.hashes, r.opts)
+	if err != nil {
+		return err
+	}
+
+	r.reports = reports
+
+	return io.EOF
+}
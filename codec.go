@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Codecs is a registry of functions carrying values of a given type as deb822
+// field text, for types from other libraries that do not implement
+// encoding.TextMarshaler and encoding.TextUnmarshaler, such as url.URL, or
+// that should be carried differently. It is handed to an Encoder with
+// WithEncoderCodecs and to a Decoder with WithDecoderCodecs.
+//
+// A codec is looked up by the exact type of a value, and takes precedence over
+// the value's own MarshalText and UnmarshalText methods and over the built-in
+// handling of strings, numbers and booleans. It also applies to the entries of
+// plain slices and maps, and to those of containers implementing
+// CodecMarshaler and CodecUnmarshaler, such as the types of the list package.
+//
+// The zero value is an empty registry, ready to use. Codecs must not be
+// registered while an Encoder or Decoder uses the registry.
+type Codecs struct {
+	marshal   map[reflect.Type]func(reflect.Value) (string, error)
+	unmarshal map[reflect.Type]func(reflect.Value, string) error
+}
+
+// RegisterCodec registers the functions carrying values of type T as field
+// text with codecs, replacing those registered before. Either function may be
+// nil, leaving that direction to the built-in handling:
+//
+//	var codecs deb822.Codecs
+//	deb822.RegisterCodec(&codecs, func(u url.URL) (string, error) {
+//		return u.String(), nil
+//	}, func(text string) (url.URL, error) {
+//		u, err := url.Parse(text)
+//		if err != nil {
+//			return url.URL{}, err
+//		}
+//		return *u, nil
+//	})
+func RegisterCodec[T any](codecs *Codecs, marshal func(T) (string, error), unmarshal func(string) (T, error)) {
+	t := reflect.TypeFor[T]()
+
+	if marshal != nil {
+		if codecs.marshal == nil {
+			codecs.marshal = make(map[reflect.Type]func(reflect.Value) (string, error))
+		}
+
+		codecs.marshal[t] = func(value reflect.Value) (string, error) {
+			return marshal(value.Interface().(T))
+		}
+	}
+
+	if unmarshal != nil {
+		if codecs.unmarshal == nil {
+			codecs.unmarshal = make(map[reflect.Type]func(reflect.Value, string) error)
+		}
+
+		codecs.unmarshal[t] = func(value reflect.Value, text string) error {
+			parsed, err := unmarshal(text)
+			if err != nil {
+				return err
+			}
+
+			value.Set(reflect.ValueOf(&parsed).Elem())
+
+			return nil
+		}
+	}
+}
+
+// Marshal renders v with the codec registered for its type. The boolean
+// result is false when there is none.
+func (c *Codecs) Marshal(v any) (string, bool, error) {
+	marshal := c.marshaler(reflect.TypeOf(v))
+	if marshal == nil {
+		return "", false, nil
+	}
+
+	text, err := marshal(reflect.ValueOf(v))
+
+	return text, true, err
+}
+
+// Unmarshal parses text into the value v points to, with the codec registered
+// for its type. The boolean result is false when there is none.
+func (c *Codecs) Unmarshal(text string, v any) (bool, error) {
+	pointer := reflect.ValueOf(v)
+	if pointer.Kind() != reflect.Pointer || pointer.IsNil() {
+		return false, fmt.Errorf("cannot unmarshal into %T", v)
+	}
+
+	unmarshal := c.unmarshaler(pointer.Type().Elem())
+	if unmarshal == nil {
+		return false, nil
+	}
+
+	return true, unmarshal(pointer.Elem(), text)
+}
+
+// marshaler returns the function rendering values of type t, or nil.
+func (c *Codecs) marshaler(t reflect.Type) func(reflect.Value) (string, error) {
+	if c == nil {
+		return nil
+	}
+
+	return c.marshal[t]
+}
+
+// unmarshaler returns the function parsing values of type t, or nil.
+func (c *Codecs) unmarshaler(t reflect.Type) func(reflect.Value, string) error {
+	if c == nil {
+		return nil
+	}
+
+	return c.unmarshal[t]
+}
+
+// has reports whether a codec is registered for t in either direction.
+func (c *Codecs) has(t reflect.Type) bool {
+	return c.marshaler(t) != nil || c.unmarshaler(t) != nil
+}
+
+// CodecMarshaler is implemented by containers whose entries are rendered with
+// the Codecs of the Encoder, such as the types of the list package. The
+// Encoder calls MarshalTextWith in place of MarshalText when it has codecs.
+type CodecMarshaler interface {
+	MarshalTextWith(codecs *Codecs) ([]byte, error)
+}
+
+// CodecUnmarshaler is implemented by containers whose entries are parsed with
+// the Codecs of the Decoder, such as the types of the list package. The
+// Decoder calls UnmarshalTextWith in place of UnmarshalText when it has
+// codecs.
+type CodecUnmarshaler interface {
+	UnmarshalTextWith(text []byte, codecs *Codecs) error
+}
@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+	"oaklab.hu/debian/deb822/types/list"
+)
+
+// mirror maps fields onto third-party types: url.URL renders itself as
+// neither text nor anything else the walker knows.
+type mirror struct {
+	Homepage  url.URL                      `debian:"Homepage"`
+	Archive   *url.URL                     `debian:"Archive,omitempty"`
+	Fallbacks list.SpaceDelimited[url.URL] `debian:"Fallbacks,omitempty"`
+	Ports     []url.URL                    `debian:"Ports,list=comma,omitempty"`
+	Address   netip.Addr                   `debian:"Address"`
+}
+
+func testCodecs() *deb822.Codecs {
+	var codecs deb822.Codecs
+
+	deb822.RegisterCodec(&codecs, func(u url.URL) (string, error) {
+		return u.String(), nil
+	}, func(text string) (url.URL, error) {
+		u, err := url.Parse(text)
+		if err != nil {
+			return url.URL{}, err
+		}
+
+		return *u, nil
+	})
+
+	// netip.Addr does render itself as text, but a codec still comes first.
+	deb822.RegisterCodec(&codecs, func(addr netip.Addr) (string, error) {
+		return addr.StringExpanded(), nil
+	}, nil)
+
+	return &codecs
+}
+
+func TestCodecs(t *testing.T) {
+	input := `Homepage: https://www.debian.org/
+Archive: http://deb.debian.org/debian
+Fallbacks: http://ftp.de.debian.org/debian http://ftp.hu.debian.org/debian
+Ports: http://deb.debian.org/debian-ports, http://ftp.ports.debian.org/debian-ports
+Address: 2001:db8::1
+`
+
+	codecs := testCodecs()
+
+	var decoded mirror
+	require.NoError(t, deb822.Unmarshal([]byte(input), &decoded, deb822.WithDecoderCodecs(codecs)))
+	require.Equal(t, "www.debian.org", decoded.Homepage.Host)
+	require.Equal(t, "/debian", decoded.Archive.Path)
+	require.Len(t, decoded.Fallbacks, 2)
+	require.Equal(t, "ftp.hu.debian.org", decoded.Fallbacks[1].Host)
+	require.Len(t, decoded.Ports, 2)
+	require.Equal(t, "/debian-ports", decoded.Ports[0].Path)
+	require.Equal(t, netip.MustParseAddr("2001:db8::1"), decoded.Address)
+
+	var sb strings.Builder
+	encoder, err := deb822.NewEncoder(&sb, nil, deb822.WithEncoderCodecs(codecs))
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(decoded))
+	require.NoError(t, encoder.Close())
+
+	require.Equal(t, strings.Replace(input, "2001:db8::1", "2001:0db8:0000:0000:0000:0000:0000:0001", 1), sb.String())
+
+	t.Run("without codecs", func(t *testing.T) {
+		var decoded mirror
+		err := deb822.Unmarshal([]byte(input), &decoded)
+		require.ErrorContains(t, err, `field "Homepage": unsupported type: url.URL`)
+	})
+
+	t.Run("direct", func(t *testing.T) {
+		text, ok, err := codecs.Marshal(decoded.Homepage)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "https://www.debian.org/", text)
+
+		_, ok, err = codecs.Marshal("not registered")
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		var u url.URL
+		ok, err = codecs.Unmarshal("https://salsa.debian.org/", &u)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, "salsa.debian.org", u.Host)
+	})
+}
@@ -36,11 +36,42 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"reflect"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 )
 
+// Unmarshaler is implemented by types that fill themselves from a stanza, for
+// example to check a Description-md5 or to derive fields from others. The
+// Decoder hands the whole stanza to UnmarshalDeb822 in place of walking the
+// fields of such a value.
+//
+// As with Marshaler, an implementation can still have the struct tags do most
+// of the work through UnmarshalStanza and a type without the method:
+//
+//	func (p *Package) UnmarshalDeb822(stanza deb822.Stanza) error {
+//		type plain Package
+//		if err := deb822.UnmarshalStanza(stanza, (*plain)(p)); err != nil {
+//			return err
+//		}
+//		...
+//	}
+type Unmarshaler interface {
+	UnmarshalDeb822(stanza Stanza) error
+}
+
+// UnmarshalStanza fills the struct, or Unmarshaler, v points to from a
+// Stanza, as a Decoder configured with opts would.
+func UnmarshalStanza(stanza Stanza, v any, opts ...ReaderOption) error {
+	into := reflect.ValueOf(v)
+	if into.Kind() != reflect.Ptr || into.IsNil() {
+		return errors.New("can't decode into a non-pointer")
+	}
+
+	return decodeStruct(stanza, into, newReaderOptions(opts))
+}
+
 func Unmarshal(data []byte, v any, opts ...ReaderOption) error {
 	decoder, err := NewDecoder(bytes.NewReader(data), openpgp.EntityList{}, opts...)
 	if err != nil {
@@ -64,11 +95,35 @@ func NewDecoder(reader io.Reader, keyring openpgp.EntityList, opts ...ReaderOpti
 	return &ret, nil
 }
 
+// Create a new Decoder from a document and its detached signature, such as a
+// `Release` file and its `Release.gpg`. See NewDetachedStanzaReader.
+func NewDetachedDecoder(reader, signature io.Reader, keyring openpgp.EntityList, opts ...ReaderOption) (*Decoder, error) {
+	var ret Decoder
+	pr, err := NewDetachedStanzaReader(reader, signature, keyring, opts...)
+	if err != nil {
+		return nil, err
+	}
+	ret.stanzaReader = *pr
+	return &ret, nil
+}
+
 // Return the Entity (if one exists) that signed this set of stanzas.
 func (d *Decoder) Signer() *openpgp.Entity {
 	return d.stanzaReader.Signer()
 }
 
+// Return the report of the signature (if one exists) over this set of
+// stanzas. See StanzaReader.SignatureReport.
+func (d *Decoder) SignatureReport() *SignatureReport {
+	return d.stanzaReader.SignatureReport()
+}
+
+// Return the reports of every signature over this set of stanzas that
+// verified. See StanzaReader.SignatureReports.
+func (d *Decoder) SignatureReports() []*SignatureReport {
+	return d.stanzaReader.SignatureReports()
+}
+
 func (d *Decoder) Decode(v any) error {
 	into := reflect.ValueOf(v)
 
@@ -76,23 +131,91 @@ func (d *Decoder) Decode(v any) error {
 		return errors.New("can't decode into a non-pointer")
 	}
 
-	switch into.Elem().Type().Kind() {
-	case reflect.Struct:
+	_, unmarshaler := v.(Unmarshaler)
+
+	switch {
+	case unmarshaler, into.Elem().Type().Kind() == reflect.Struct:
 		paragraph, err := d.stanzaReader.Next()
 		if err != nil {
 			return err
 		}
-		return decodeStruct(*paragraph, into)
-	case reflect.Slice:
+		return d.stanzaReader.locate(decodeStruct(*paragraph, into, d.stanzaReader.opts))
+	case into.Elem().Type().Kind() == reflect.Slice:
 		return d.decodeSlice(into)
 	default:
 		return fmt.Errorf("can't decode into a %s", into.Elem().Type().Name())
 	}
 }
 
+// Items decodes the stanzas of reader one at a time into values of the struct
+// type T, for use with a range loop:
+//
+//	for pkg, err := range deb822.Items[types.Package](r, keyring) {
+//		...
+//	}
+//
+// The input is streamed, honouring opts like NewDecoder does. Iteration stops
+// after the last stanza, or after yielding the first error. Use ItemsOf with a
+// Decoder of your own to inspect its signature afterwards.
+func Items[T any](reader io.Reader, keyring openpgp.EntityList, opts ...ReaderOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		decoder, err := NewDecoder(reader, keyring, opts...)
+		if err != nil {
+			var zero T
+			yield(zero, err)
+			return
+		}
+
+		ItemsOf[T](decoder)(yield)
+	}
+}
+
+// ItemsOf decodes the remaining stanzas of a Decoder one at a time into values
+// of the struct type T. See Items.
+//
+// Under WithWorkers the stanzas are decoded ahead on several goroutines, and
+// those read ahead are lost if the loop is left early.
+func ItemsOf[T any](d *Decoder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		if d.stanzaReader.opts.workers > 1 {
+			err := d.decodeParallel(reflect.TypeFor[T](), func(value reflect.Value) bool {
+				return yield(value.Interface().(T), nil)
+			})
+			if err != nil {
+				var zero T
+				yield(zero, err)
+			}
+
+			return
+		}
+
+		for {
+			var item T
+			err := d.Decode(&item)
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(item, err)
+				return
+			}
+
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (d *Decoder) decodeSlice(into reflect.Value) error {
 	flavor := into.Elem().Type().Elem()
 
+	if d.stanzaReader.opts.workers > 1 {
+		return d.decodeParallel(flavor, func(value reflect.Value) bool {
+			into.Elem().Set(reflect.Append(into.Elem(), value))
+			return true
+		})
+	}
+
 	for {
 		targetValue := reflect.New(flavor)
 
@@ -104,8 +227,8 @@ func (d *Decoder) decodeSlice(into reflect.Value) error {
 			return err
 		}
 
-		if err := decodeStruct(*stanza, targetValue); err != nil {
-			return err
+		if err := decodeStruct(*stanza, targetValue, d.stanzaReader.opts); err != nil {
+			return d.stanzaReader.locate(err)
 		}
 		into.Elem().Set(reflect.Append(into.Elem(), targetValue.Elem()))
 	}
@@ -113,12 +236,30 @@ func (d *Decoder) decodeSlice(into reflect.Value) error {
 }
 
 // decodeStruct fills a struct from a Stanza, honouring the debian struct tags
-// (see TagKey).
-func decodeStruct(stanza Stanza, into reflect.Value) error {
+// (see TagKey), or hands the Stanza to the UnmarshalDeb822 method of into.
+// Errors of the method are wrapped into a ParseError, so that they get
+// located in the input.
+func decodeStruct(stanza Stanza, into reflect.Value, opts readerOptions) error {
 	// If we have a pointer, let's follow it.
 	if into.Type().Kind() == reflect.Ptr {
-		return decodeStruct(stanza, into.Elem())
+		if unmarshaler, ok := into.Interface().(Unmarshaler); ok {
+			err := unmarshaler.UnmarshalDeb822(stanza)
+
+			var perr *ParseError
+			if err != nil && !errors.As(err, &perr) {
+				err = &ParseError{Err: err}
+			}
+
+			return err
+		}
+
+		elem := into.Elem()
+		if elem.Kind() == reflect.Ptr && elem.IsNil() {
+			elem.Set(reflect.New(elem.Type().Elem()))
+		}
+
+		return decodeStruct(stanza, elem, opts)
 	}
 
-	return unmarshalStanza(stanza, into)
+	return unmarshalStanza(stanza, into, opts)
 }
@@ -32,6 +32,7 @@
 package deb822_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -81,6 +82,68 @@ Value: Baz
 	require.Equal(t, "foo", foo[0].Value)
 }
 
+func TestItems(t *testing.T) {
+	input := `Value: foo
+
+Value: Bar
+
+Value: Baz
+`
+
+	var values []string
+	for item, err := range deb822.Items[TestStruct](strings.NewReader(input), nil) {
+		require.NoError(t, err)
+		values = append(values, item.Value)
+	}
+	require.Equal(t, []string{"foo", "Bar", "Baz"}, values)
+
+	t.Run("Break", func(t *testing.T) {
+		decoder, err := deb822.NewDecoder(strings.NewReader(input), nil)
+		require.NoError(t, err)
+
+		for item := range deb822.ItemsOf[TestStruct](decoder) {
+			require.Equal(t, "foo", item.Value)
+			break
+		}
+
+		// The decoder carries on where the loop left off.
+		var rest []TestStruct
+		require.NoError(t, decoder.Decode(&rest))
+		require.Len(t, rest, 2)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		var errs int
+		for _, err := range deb822.Items[TestStruct](strings.NewReader("Value: foo\nValue: bar\n"), nil, deb822.WithStrict()) {
+			require.ErrorIs(t, err, deb822.ErrDuplicateField)
+			errs++
+		}
+		require.Equal(t, 1, errs)
+	})
+}
+
+func TestDisallowUnknownFields(t *testing.T) {
+	input := []byte(`Value: foo
+Build-Depend: typo
+Value-Two: bar
+Valu-Three: typo
+`)
+
+	var lenient TestStruct
+	require.NoError(t, deb822.Unmarshal(input, &lenient))
+
+	var strict TestStruct
+	err := deb822.Unmarshal(input, &strict, deb822.WithDisallowUnknownFields())
+	require.ErrorIs(t, err, deb822.ErrUnknownField)
+	require.ErrorContains(t, err, `line 2: stanza 1: field "Build-Depend": unknown field`)
+	require.ErrorContains(t, err, `line 4: stanza 1: field "Valu-Three": unknown field`)
+
+	// The known fields are decoded all the same.
+	require.Equal(t, lenient, strict)
+
+	require.NoError(t, deb822.Unmarshal([]byte("value: foo\nFnord-Foo-Bar: baz\n"), &strict, deb822.WithDisallowUnknownFields()))
+}
+
 func TestTagUnmarshal(t *testing.T) {
 	var foo TestStruct
 	require.NoError(t, deb822.Unmarshal([]byte(`Value: foo
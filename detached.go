@@ -0,0 +1,273 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"hash"
+	"io"
+	"slices"
+	"strconv"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// Create a new StanzaReader from a document and its detached signature, such
+// as a `Release` file and its `Release.gpg`. The signature may be armored or
+// binary.
+//
+// The pair is verified against `keyring` the way clearsigned input is: the
+// whole document is read and checked before the first stanza is returned,
+// unless WithStreamingVerification is given, and the signature is reported by
+// Signer and SignatureReport. A signature that none of the keys in `keyring`
+// made fails with an OpenPGP error.
+func NewDetachedStanzaReader(reader, signature io.Reader, keyring openpgp.EntityList, opts ...ReaderOption) (*StanzaReader, error) {
+	pr := StanzaReader{
+		opts: newReaderOptions(opts),
+	}
+
+	detached, err := newDetachedReader(reader, signature, keyring, pr.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr.opts.streaming {
+		pr.stream = &detached.streamResult
+		pr.reader = bufio.NewReader(detached)
+
+		return &pr, nil
+	}
+
+	signedData, err := io.ReadAll(detached)
+	if err != nil {
+		return nil, err
+	}
+
+	pr.reports = detached.reports
+	pr.reader = bufio.NewReader(bytes.NewReader(signedData))
+
+	return &pr, nil
+}
+
+// detachedReader passes a signed document through unchanged, digesting it on
+// the way, and checks its detached signatures when it reaches the end: the
+// read that would otherwise return io.EOF returns the verification error
+// instead, if there is one.
+type detachedReader struct {
+	source io.Reader
+	policy SignaturePolicy
+	checks []*signatureCheck
+
+	// digestWriter feeds the digest of every signature, canonicalising line
+	// endings for text signatures.
+	digestWriter io.Writer
+
+	// err is sticky once the end of source has been reached.
+	err error
+
+	streamResult
+}
+
+// newDetachedReader reads the detached signatures up front, so that the
+// document can be digested with the algorithms they name as it streams past.
+func newDetachedReader(source, signature io.Reader, keyring openpgp.EntityList, opts readerOptions) (*detachedReader, error) {
+	body, err := signatureBody(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	checks, err := readSignatures(keyring, body, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	writers := make([]io.Writer, 0, len(checks))
+	for _, check := range checks {
+		if check.digest, err = check.sig.PrepareVerify(); err != nil {
+			return nil, err
+		}
+
+		switch check.sig.SigType {
+		case packet.SigTypeBinary:
+			writers = append(writers, check.digest)
+		case packet.SigTypeText:
+			writers = append(writers, openpgp.NewCanonicalTextHash(check.digest))
+		default:
+			return nil, pgperrors.UnsupportedError("unsupported signature type: " + strconv.Itoa(int(check.sig.SigType)))
+		}
+	}
+
+	return &detachedReader{
+		source:       source,
+		policy:       opts.policy,
+		checks:       checks,
+		digestWriter: io.MultiWriter(writers...),
+	}, nil
+}
+
+// Read hands out the document as it is, checking the signatures at its end.
+func (r *detachedReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	n, err := r.source.Read(p)
+	_, _ = r.digestWriter.Write(p[:n])
+
+	if err == io.EOF {
+		r.err = r.verify()
+		return n, r.err
+	} else if err != nil {
+		r.err = err
+	}
+
+	return n, err
+}
+
+// verify checks the signatures against the digest of the whole document.
+func (r *detachedReader) verify() error {
+	reports, err := verifySignatures(r.checks, r.policy)
+	if err != nil {
+		return err
+	}
+
+	r.reports = reports
+
+	return io.EOF
+}
+
+// signatureBody returns the packets of a detached signature, taking the
+// armor off if it has one.
+func signatureBody(signature io.Reader) (io.Reader, error) {
+	bufioSignature := bufio.NewReader(signature)
+
+	line, _ := bufioSignature.Peek(15)
+	if string(line) != "-----BEGIN PGP " {
+		return bufioSignature, nil
+	}
+
+	block, err := armor.Decode(bufioSignature)
+	if err != nil {
+		return nil, err
+	}
+
+	if block.Type != openpgp.SignatureType {
+		return nil, pgperrors.InvalidArgumentError("expected '" + openpgp.SignatureType + "', got: " + block.Type)
+	}
+
+	return block.Body, nil
+}
+
+// detachedSigner digests a document as it is written and, once closed, writes
+// an armored detached signature over it by each of its keys, as gpg
+// --detach-sign --armor does for a `Release.gpg`.
+type detachedSigner struct {
+	out    io.Writer
+	config *packet.Config
+	keys   []*packet.PrivateKey
+	sigs   []*packet.Signature
+	hashes []hash.Hash
+	writer io.Writer
+}
+
+// newDetachedSigner prepares a binary signature by each of the given keys,
+// writing them to out once the signer is closed.
+func newDetachedSigner(out io.Writer, keys []*packet.PrivateKey, config *packet.Config) (*detachedSigner, error) {
+	s := &detachedSigner{out: out, config: config, keys: keys}
+
+	writers := make([]io.Writer, 0, len(keys))
+	for _, key := range keys {
+		if key.Encrypted {
+			return nil, pgperrors.InvalidArgumentError("signing key " + key.KeyIdString() + " is encrypted")
+		}
+
+		sig := &packet.Signature{
+			Version:           key.Version,
+			SigType:           packet.SigTypeBinary,
+			PubKeyAlgo:        key.PubKeyAlgo,
+			Hash:              signingHash(&key.PublicKey, config),
+			CreationTime:      config.Now(),
+			IssuerKeyId:       &key.KeyId,
+			IssuerFingerprint: key.Fingerprint,
+			Notations:         config.Notations(),
+		}
+
+		h, err := sig.PrepareSign(config)
+		if err != nil {
+			return nil, err
+		}
+
+		s.sigs = append(s.sigs, sig)
+		s.hashes = append(s.hashes, h)
+		writers = append(writers, h)
+	}
+
+	s.writer = io.MultiWriter(writers...)
+
+	return s, nil
+}
+
+// signingHash returns the digest algorithm to sign with a key, choosing it the
+// way clearsign.EncodeMulti does so that a detached signature and the
+// clearsigned output agree: the configured one if the key accepts it, or else
+// the first one the key accepts.
+func signingHash(key *packet.PublicKey, config *packet.Config) crypto.Hash {
+	accepted := []crypto.Hash{crypto.SHA256, crypto.SHA384, crypto.SHA512, crypto.SHA3_256, crypto.SHA3_512}
+
+	switch key.PubKeyAlgo {
+	case packet.PubKeyAlgoEd448:
+		accepted = []crypto.Hash{crypto.SHA512, crypto.SHA3_512}
+	case packet.PubKeyAlgoECDSA, packet.PubKeyAlgoEdDSA:
+		if curve, err := key.Curve(); err == nil {
+			switch curve {
+			case packet.Curve448, packet.CurveNistP521, packet.CurveBrainpoolP512:
+				accepted = []crypto.Hash{crypto.SHA512, crypto.SHA3_512}
+			case packet.CurveBrainpoolP384, packet.CurveNistP384:
+				accepted = []crypto.Hash{crypto.SHA384, crypto.SHA512, crypto.SHA3_512}
+			}
+		}
+	}
+
+	if hash := config.Hash(); slices.Contains(accepted, hash) {
+		return hash
+	}
+
+	return accepted[0]
+}
+
+// Write feeds the document to every signature's digest.
+func (s *detachedSigner) Write(p []byte) (int, error) {
+	return s.writer.Write(p)
+}
+
+// Close signs the digests and writes out the armored signatures.
+func (s *detachedSigner) Close() error {
+	armored, err := armor.Encode(s.out, openpgp.SignatureType, nil)
+	if err != nil {
+		return err
+	}
+
+	for i, sig := range s.sigs {
+		if err := sig.Sign(s.hashes[i], s.keys[i], s.config); err != nil {
+			return err
+		}
+
+		if err := sig.Serialize(armored); err != nil {
+			return err
+		}
+	}
+
+	return armored.Close()
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+)
+
+const detachedRelease = `Origin: Debian
+Suite: stable
+Codename: bookworm
+
+Origin: Debian
+Suite: testing
+Codename: trixie
+`
+
+func newTestEntity(t *testing.T) *openpgp.Entity {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("test", "", "", &packet.Config{RSABits: 1024}) // insecure for testing
+	require.NoError(t, err)
+
+	return entity
+}
+
+func TestDetachedStanzaReader(t *testing.T) {
+	entity := newTestEntity(t)
+	keyring := openpgp.EntityList{entity}
+
+	signers := map[string]func(w io.Writer, signer *openpgp.Entity, message io.Reader, config *packet.Config) error{
+		"armored":      openpgp.ArmoredDetachSign,
+		"binary":       openpgp.DetachSign,
+		"armored text": openpgp.ArmoredDetachSignText,
+	}
+
+	for name, sign := range signers {
+		t.Run(name, func(t *testing.T) {
+			var signature bytes.Buffer
+			require.NoError(t, sign(&signature, entity, strings.NewReader(detachedRelease), nil))
+
+			for _, opts := range [][]deb822.ReaderOption{nil, {deb822.WithStreamingVerification()}} {
+				reader, err := deb822.NewDetachedStanzaReader(
+					strings.NewReader(detachedRelease), bytes.NewReader(signature.Bytes()), keyring, opts...,
+				)
+				require.NoError(t, err)
+
+				blocks, err := reader.All()
+				require.NoError(t, err)
+				require.Len(t, blocks, 2)
+				require.Equal(t, "trixie", blocks[1].Values["Codename"])
+				require.Equal(t, entity, reader.Signer())
+			}
+		})
+	}
+}
+
+func TestDetachedStanzaReaderRejects(t *testing.T) {
+	entity := newTestEntity(t)
+
+	var signature bytes.Buffer
+	require.NoError(t, openpgp.ArmoredDetachSign(&signature, entity, strings.NewReader(detachedRelease), nil))
+
+	tampered := strings.Replace(detachedRelease, "trixie", "sid", 1)
+
+	t.Run("tampered", func(t *testing.T) {
+		_, err := deb822.NewDetachedStanzaReader(
+			strings.NewReader(tampered), bytes.NewReader(signature.Bytes()), openpgp.EntityList{entity},
+		)
+		require.Error(t, err)
+	})
+
+	t.Run("tampered while streaming", func(t *testing.T) {
+		reader, err := deb822.NewDetachedStanzaReader(
+			strings.NewReader(tampered), bytes.NewReader(signature.Bytes()), openpgp.EntityList{entity},
+			deb822.WithStreamingVerification(),
+		)
+		require.NoError(t, err)
+
+		_, err = reader.All()
+		require.Error(t, err)
+		require.Nil(t, reader.Signer())
+	})
+
+	t.Run("unknown signer", func(t *testing.T) {
+		_, err := deb822.NewDetachedStanzaReader(
+			strings.NewReader(detachedRelease), bytes.NewReader(signature.Bytes()), openpgp.EntityList{newTestEntity(t)},
+		)
+		require.Error(t, err)
+	})
+}
+
+func TestDetachedDecoder(t *testing.T) {
+	entity := newTestEntity(t)
+
+	var signature bytes.Buffer
+	require.NoError(t, openpgp.DetachSign(&signature, entity, strings.NewReader(detachedRelease), nil))
+
+	decoder, err := deb822.NewDetachedDecoder(
+		strings.NewReader(detachedRelease), &signature, openpgp.EntityList{entity},
+	)
+	require.NoError(t, err)
+	require.Equal(t, entity, decoder.Signer())
+
+	var releases []struct {
+		Suite    string
+		Codename string
+	}
+	require.NoError(t, decoder.Decode(&releases))
+	require.Len(t, releases, 2)
+	require.Equal(t, "bookworm", releases[0].Codename)
+}
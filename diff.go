@@ -0,0 +1,298 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrPatchMismatch is returned by Stanza.Apply, wrapped with the field
+// concerned, for a change that does not fit the stanza: a field to add that is
+// already there, or one to change or remove that is missing or holds another
+// value than the change expects.
+var ErrPatchMismatch = errors.New("change does not apply")
+
+// ChangeKind is the kind of a FieldChange.
+type ChangeKind int
+
+const (
+	// FieldAdded is a field that only the newer stanza has.
+	FieldAdded ChangeKind = iota + 1
+	// FieldRemoved is a field that only the older stanza has.
+	FieldRemoved
+	// FieldChanged is a field whose value differs.
+	FieldChanged
+	// FieldMoved is a field that comes at another place among the others.
+	FieldMoved
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case FieldAdded:
+		return "added"
+	case FieldRemoved:
+		return "removed"
+	case FieldChanged:
+		return "changed"
+	case FieldMoved:
+		return "moved"
+	}
+
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+// FieldChange is a difference between two stanzas, in a single field. A field
+// both moved and changed takes two changes.
+type FieldChange struct {
+	Kind  ChangeKind
+	Field string
+
+	// Old is the value of a removed or changed field before the change, and
+	// New the value of an added or changed field after it.
+	Old, New string
+
+	// After is the field an added or moved field follows, or "" when it comes
+	// first.
+	After string
+}
+
+// Diff returns the changes that turn one stanza into another. Removed fields
+// come first, in the order of from; added, changed and moved fields follow in
+// the order of to. Applying the changes to from gives to, its field order
+// included. Field names are compared exactly, the way the parser keeps fields
+// whose names only differ in case apart in lenient mode.
+//
+// Fields that keep their order relative to each other are not moved: only
+// those off the longest run of fields the two stanzas have in the same order
+// are.
+func Diff(from, to Stanza) []FieldChange {
+	var changes []FieldChange
+
+	for _, field := range from.Order {
+		if _, found := to.Values[field]; !found {
+			changes = append(changes, FieldChange{Kind: FieldRemoved, Field: field, Old: from.Values[field]})
+		}
+	}
+
+	kept := keptFields(from, to)
+
+	after := ""
+	for _, field := range to.Order {
+		value := to.Values[field]
+
+		old, found := from.Values[field]
+		if !found {
+			changes = append(changes, FieldChange{Kind: FieldAdded, Field: field, New: value, After: after})
+		} else {
+			if old != value {
+				changes = append(changes, FieldChange{Kind: FieldChanged, Field: field, Old: old, New: value})
+			}
+
+			if _, stays := kept[field]; !stays {
+				changes = append(changes, FieldChange{Kind: FieldMoved, Field: field, After: after})
+			}
+		}
+
+		after = field
+	}
+
+	return changes
+}
+
+// keptFields returns the fields two stanzas have in common that stay in place
+// between them: the longest common subsequence of their orders.
+func keptFields(from, to Stanza) map[string]struct{} {
+	var a, b []string
+	for _, field := range from.Order {
+		if _, found := to.Values[field]; found {
+			a = append(a, field)
+		}
+	}
+	for _, field := range to.Order {
+		if _, found := from.Values[field]; found {
+			b = append(b, field)
+		}
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	kept := make(map[string]struct{}, lengths[0][0])
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			kept[a[i]] = struct{}{}
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+
+	return kept
+}
+
+// Apply applies changes, such as those Diff returns, in turn. It checks every
+// change against the stanza first, and fails with ErrPatchMismatch, leaving
+// the stanza as it was, if one does not fit.
+func (p *Stanza) Apply(changes []FieldChange) error {
+	patched := p.Clone()
+
+	for _, change := range changes {
+		if err := patched.apply(change); err != nil {
+			return fmt.Errorf("field %q: %w", change.Field, err)
+		}
+	}
+
+	*p = patched
+
+	return nil
+}
+
+// apply applies a single change.
+func (p *Stanza) apply(change FieldChange) error {
+	value, found := p.Values[change.Field]
+
+	switch change.Kind {
+	case FieldAdded:
+		if found {
+			return fmt.Errorf("%w: already present", ErrPatchMismatch)
+		}
+
+		return p.insertAfter(change.After, change.Field, change.New)
+	case FieldRemoved, FieldChanged:
+		if !found {
+			return fmt.Errorf("%w: not present", ErrPatchMismatch)
+		} else if value != change.Old {
+			return fmt.Errorf("%w: value differs", ErrPatchMismatch)
+		}
+
+		if change.Kind == FieldRemoved {
+			p.remove(change.Field)
+		} else {
+			p.Values[change.Field] = change.New
+		}
+
+		return nil
+	case FieldMoved:
+		if !found {
+			return fmt.Errorf("%w: not present", ErrPatchMismatch)
+		} else if change.After == change.Field {
+			return fmt.Errorf("%w: cannot follow itself", ErrPatchMismatch)
+		}
+
+		p.remove(change.Field)
+
+		return p.insertAfter(change.After, change.Field, value)
+	}
+
+	return fmt.Errorf("%w: unknown kind of change %s", ErrPatchMismatch, change.Kind)
+}
+
+// Conflict is a field that both sides of a Merge changed, each in its own way.
+// Ours and Theirs are the changes of either side from the base.
+type Conflict struct {
+	Field        string
+	Ours, Theirs FieldChange
+}
+
+// Merge merges the changes two stanzas made to a common base, such as a
+// debian/control paragraph edited by hand (ours) and by a tool (theirs). It
+// takes ours and applies the changes of theirs to it, except for those to
+// fields ours changed differently: those keep the value of ours, and are
+// reported as conflicts, in the order of theirs. A field both sides changed
+// the same way is not a conflict.
+//
+// Field order follows ours. Fields only theirs added go after the field they
+// follow in theirs, or the closest one before it that the result has.
+func Merge(base, ours, theirs Stanza) (Stanza, []Conflict) {
+	merged := ours.Clone()
+
+	mine := make(map[string]FieldChange)
+	for _, change := range Diff(base, ours) {
+		if change.Kind != FieldMoved {
+			mine[change.Field] = change
+		}
+	}
+
+	var conflicts []Conflict
+	for _, change := range Diff(base, theirs) {
+		if change.Kind == FieldMoved {
+			continue
+		}
+
+		if own, touched := mine[change.Field]; touched {
+			if own.Kind != change.Kind || own.New != change.New {
+				conflicts = append(conflicts, Conflict{Field: change.Field, Ours: own, Theirs: change})
+			}
+
+			continue
+		}
+
+		switch change.Kind {
+		case FieldRemoved:
+			merged.remove(change.Field)
+		case FieldChanged:
+			merged.Values[change.Field] = change.New
+		case FieldAdded:
+			after := ""
+			for i := slices.Index(theirs.Order, change.Field) - 1; i >= 0; i-- {
+				if _, found := merged.Values[theirs.Order[i]]; found {
+					after = theirs.Order[i]
+					break
+				}
+			}
+
+			_ = merged.insertAfter(after, change.Field, change.New)
+		}
+	}
+
+	return merged, conflicts
+}
+
+// insertAfter inserts a field after another one, or first when after is
+// empty. The field must not be present yet.
+func (p *Stanza) insertAfter(after, key, value string) error {
+	at := 0
+	if after != "" {
+		i := slices.Index(p.Order, after)
+		if i < 0 {
+			return fmt.Errorf("%w: no field %q to follow", ErrPatchMismatch, after)
+		}
+
+		at = i + 1
+	}
+
+	if p.Values == nil {
+		p.Values = make(map[string]string)
+	}
+
+	p.Order = slices.Insert(p.Order, at, key)
+	p.Values[key] = value
+
+	return nil
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+)
+
+// stanza parses a single stanza.
+func stanza(t *testing.T, text string) deb822.Stanza {
+	t.Helper()
+
+	reader, err := deb822.NewStanzaReader(strings.NewReader(text), nil)
+	require.NoError(t, err)
+
+	s, err := reader.Next()
+	require.NoError(t, err)
+
+	return *s
+}
+
+func TestDiff(t *testing.T) {
+	from := stanza(t, `Package: hello
+Version: 2.10-2
+Architecture: amd64
+Maintainer: Santiago Vila <sanvila@debian.org>
+Installed-Size: 280
+Depends: libc6 (>= 2.34)
+`)
+	to := stanza(t, `Package: hello
+Architecture: amd64
+Version: 2.10-3
+Maintainer: Santiago Vila <sanvila@debian.org>
+Depends: libc6 (>= 2.34)
+Homepage: https://www.gnu.org/software/hello/
+`)
+
+	changes := deb822.Diff(from, to)
+	require.Equal(t, []deb822.FieldChange{
+		{Kind: deb822.FieldRemoved, Field: "Installed-Size", Old: "280"},
+		{Kind: deb822.FieldChanged, Field: "Version", Old: "2.10-2", New: "2.10-3"},
+		{Kind: deb822.FieldMoved, Field: "Version", After: "Architecture"},
+		{Kind: deb822.FieldAdded, Field: "Homepage", New: "https://www.gnu.org/software/hello/", After: "Depends"},
+	}, changes)
+
+	require.Empty(t, deb822.Diff(from, from))
+
+	t.Run("apply", func(t *testing.T) {
+		patched := from
+		require.NoError(t, patched.Apply(changes))
+		require.Equal(t, to, patched)
+
+		// The original is left alone.
+		require.Equal(t, "2.10-2", from.Values["Version"])
+	})
+
+	t.Run("apply to empty stanza", func(t *testing.T) {
+		var patched deb822.Stanza
+		require.NoError(t, patched.Apply(deb822.Diff(deb822.Stanza{}, to)))
+		require.Equal(t, to, patched)
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		patched := to
+		require.NoError(t, patched.Apply(deb822.Diff(to, from)))
+		require.Equal(t, from, patched)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		patched := to
+		err := patched.Apply(changes)
+		require.ErrorIs(t, err, deb822.ErrPatchMismatch)
+		require.ErrorContains(t, err, `field "Installed-Size"`)
+		require.Equal(t, to, patched)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	base := stanza(t, `Source: hello
+Section: devel
+Priority: optional
+Maintainer: Santiago Vila <sanvila@debian.org>
+Standards-Version: 4.6.0
+Build-Depends: debhelper-compat (= 13)
+`)
+
+	t.Run("clean", func(t *testing.T) {
+		// Someone reorders and edits by hand, while a tool bumps the
+		// Standards-Version and adds a Homepage.
+		ours := stanza(t, `Source: hello
+Maintainer: Santiago Vila <sanvila@debian.org>
+Section: devel
+Priority: optional
+Standards-Version: 4.6.0
+Build-Depends: debhelper-compat (= 13), texinfo
+`)
+		theirs := stanza(t, `Source: hello
+Section: devel
+Priority: optional
+Maintainer: Santiago Vila <sanvila@debian.org>
+Standards-Version: 4.7.0
+Homepage: https://www.gnu.org/software/hello/
+Build-Depends: debhelper-compat (= 13)
+`)
+
+		merged, conflicts := deb822.Merge(base, ours, theirs)
+		require.Empty(t, conflicts)
+		require.Equal(t, []string{"Source", "Maintainer", "Section", "Priority", "Standards-Version", "Homepage", "Build-Depends"}, merged.Order)
+		require.Equal(t, "4.7.0", merged.Values["Standards-Version"])
+		require.Equal(t, "debhelper-compat (= 13), texinfo", merged.Values["Build-Depends"])
+
+		// The inputs are left alone.
+		require.NotContains(t, ours.Values, "Homepage")
+	})
+
+	t.Run("same change", func(t *testing.T) {
+		ours := stanza(t, "Source: hello\nSection: devel\nMaintainer: Santiago Vila <sanvila@debian.org>\nStandards-Version: 4.7.0\nBuild-Depends: debhelper-compat (= 13)\n")
+
+		merged, conflicts := deb822.Merge(base, ours, ours)
+		require.Empty(t, conflicts)
+		require.Equal(t, ours, merged)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		ours := stanza(t, `Source: hello
+Section: devel
+Maintainer: Santiago Vila <sanvila@debian.org>
+Standards-Version: 4.6.2
+Build-Depends: debhelper-compat (= 13)
+`)
+		theirs := stanza(t, `Source: hello
+Section: devel
+Priority: extra
+Maintainer: Santiago Vila <sanvila@debian.org>
+Standards-Version: 4.7.0
+Build-Depends: debhelper-compat (= 13)
+`)
+
+		merged, conflicts := deb822.Merge(base, ours, theirs)
+		require.Equal(t, []deb822.Conflict{
+			{
+				Field:  "Priority",
+				Ours:   deb822.FieldChange{Kind: deb822.FieldRemoved, Field: "Priority", Old: "optional"},
+				Theirs: deb822.FieldChange{Kind: deb822.FieldChanged, Field: "Priority", Old: "optional", New: "extra"},
+			},
+			{
+				Field:  "Standards-Version",
+				Ours:   deb822.FieldChange{Kind: deb822.FieldChanged, Field: "Standards-Version", Old: "4.6.0", New: "4.6.2"},
+				Theirs: deb822.FieldChange{Kind: deb822.FieldChanged, Field: "Standards-Version", Old: "4.6.0", New: "4.7.0"},
+			},
+		}, conflicts)
+
+		// Conflicting fields keep our side.
+		require.Equal(t, ours, merged)
+	})
+}
@@ -0,0 +1,353 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"oaklab.hu/debian/deb822/internal/fold"
+)
+
+// A Document is a lossless representation of a deb822 file, such as a
+// `debian/control` or `debian/tests/control`: comments, blank lines,
+// indentation, field name casing and line endings are all kept, so that
+// writing a Document back out reproduces its input byte for byte.
+//
+// Fields are read and edited through the Paragraphs of the document. Only the
+// fields that are changed are rewritten, leaving the rest of the file as it
+// was.
+type Document struct {
+	// Paragraphs are the paragraphs of the document, in order.
+	Paragraphs []*Paragraph
+
+	// tail holds the blank lines and comments after the last paragraph.
+	tail string
+}
+
+// A Paragraph is a paragraph of a Document.
+type Paragraph struct {
+	// lead holds the blank lines and comments ahead of the paragraph.
+	lead   string
+	fields []*documentField
+}
+
+// documentField is a field of a Paragraph as it was written.
+type documentField struct {
+	// lead holds the comments between the previous field and this one.
+	lead string
+	// name is the field name, with the case it was written in.
+	name string
+	// raw is the field line and its continuation lines, comments between
+	// them included.
+	raw string
+}
+
+// ParseDocument reads a whole deb822 file into a Document.
+//
+// Comments are allowed anywhere and, like dpkg does, are not part of the
+// values they interrupt. The input is otherwise parsed the way a lenient
+// StanzaReader parses it; errors are ParseErrors. Clearsigned input is not
+// supported, as editing it would void its signature: edit the unsigned
+// document and sign it again.
+func ParseDocument(reader io.Reader) (*Document, error) {
+	var (
+		doc     Document
+		current *Paragraph
+		field   *documentField
+		pending strings.Builder
+		lineNo  int
+	)
+
+	bufioReader := bufio.NewReader(reader)
+
+	for {
+		line, err := bufioReader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if line == "" {
+			break
+		}
+		lineNo++
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			current, field = nil, nil
+			pending.WriteString(line)
+
+		case strings.HasPrefix(line, "#"):
+			pending.WriteString(line)
+
+		case strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t"):
+			if field == nil {
+				return nil, &ParseError{
+					Line:   lineNo,
+					Stanza: len(doc.Paragraphs) + 1,
+					Err:    fmt.Errorf("%w: '%s'", ErrUnexpectedContinuation, strings.TrimRight(line, "\r\n")),
+				}
+			}
+
+			field.raw += pending.String() + line
+			pending.Reset()
+
+		default:
+			name, _, found := strings.Cut(line, ":")
+			if !found {
+				stanza := len(doc.Paragraphs)
+				if current == nil {
+					stanza++
+				}
+
+				return nil, &ParseError{
+					Line:   lineNo,
+					Stanza: stanza,
+					Err:    fmt.Errorf("could not parse line: '%s'", line),
+				}
+			}
+
+			field = &documentField{name: strings.TrimSpace(name), raw: line}
+
+			if current == nil {
+				current = &Paragraph{lead: pending.String()}
+				doc.Paragraphs = append(doc.Paragraphs, current)
+			} else {
+				field.lead = pending.String()
+			}
+			pending.Reset()
+
+			current.fields = append(current.fields, field)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	doc.tail = pending.String()
+
+	return &doc, nil
+}
+
+// WriteTo writes the document out, reproducing its input except for the
+// fields that have been changed.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+
+	dropped := false
+	for _, p := range d.Paragraphs {
+		if len(p.fields) == 0 {
+			// A paragraph whose fields were all deleted is left out, along
+			// with the blank lines and comments ahead of it.
+			dropped = true
+			continue
+		}
+
+		lead := p.lead
+		if dropped && sb.Len() == 0 {
+			// The paragraph now comes first, and needs no separator.
+			lead = trimBlankLines(lead)
+		}
+		sb.WriteString(lead)
+
+		for _, field := range p.fields {
+			sb.WriteString(field.lead)
+			sb.WriteString(field.raw)
+		}
+	}
+
+	sb.WriteString(d.tail)
+
+	n, err := io.WriteString(w, sb.String())
+
+	return int64(n), err
+}
+
+// trimBlankLines drops the blank lines at the start of text.
+func trimBlankLines(text string) string {
+	for text != "" {
+		line, rest, _ := strings.Cut(text, "\n")
+		if strings.TrimSpace(line) != "" {
+			break
+		}
+
+		text = rest
+	}
+
+	return text
+}
+
+// String returns the document as it would be written out.
+func (d *Document) String() string {
+	var sb strings.Builder
+	_, _ = d.WriteTo(&sb)
+
+	return sb.String()
+}
+
+// Stanzas returns the values of every paragraph, as a StanzaReader would
+// have read them. Paragraphs whose fields were all deleted are left out, as
+// they are when the document is written.
+func (d *Document) Stanzas() []Stanza {
+	stanzas := make([]Stanza, 0, len(d.Paragraphs))
+	for _, p := range d.Paragraphs {
+		if len(p.fields) > 0 {
+			stanzas = append(stanzas, p.Stanza())
+		}
+	}
+
+	return stanzas
+}
+
+// Names returns the names of the fields of the paragraph, in order and in the
+// case they were written in.
+func (p *Paragraph) Names() []string {
+	names := make([]string, len(p.fields))
+	for i, field := range p.fields {
+		names[i] = field.name
+	}
+
+	return names
+}
+
+// Get returns the value of a field, matching its name case-insensitively as
+// Debian Policy 5.1 requires, and whether the paragraph has it. Values read
+// the same as they would from a StanzaReader.
+func (p *Paragraph) Get(name string) (string, bool) {
+	if field := p.field(name); field != nil {
+		return field.value(), true
+	}
+
+	return "", false
+}
+
+// Set sets the value of a field. A field the paragraph already has is
+// rewritten in place, keeping the case of its name and its line endings, and
+// left untouched if its value does not change; a new field is added at the
+// end of the paragraph. It fails with ErrInvalidFieldName or
+// ErrInvalidFieldValue, leaving the paragraph as it was, for a field that
+// would not read back as it is.
+func (p *Paragraph) Set(name, value string) error {
+	if err := validateField(name, value); err != nil {
+		return err
+	}
+
+	if field := p.field(name); field != nil {
+		if field.value() != value {
+			field.raw = renderField(field.name, value, field.eol(), strings.HasSuffix(field.raw, "\n"))
+		}
+
+		return nil
+	}
+
+	eol := "\n"
+	if n := len(p.fields); n > 0 {
+		last := p.fields[n-1]
+		eol = last.eol()
+
+		if !strings.HasSuffix(last.raw, "\n") {
+			last.raw += eol
+		}
+	}
+
+	p.fields = append(p.fields, &documentField{name: name, raw: renderField(name, value, eol, true)})
+
+	return nil
+}
+
+// Delete removes a field, along with the comments ahead of it, and reports
+// whether the paragraph had it. A paragraph left without fields is not
+// written out, nor returned by Document.Stanzas.
+func (p *Paragraph) Delete(name string) bool {
+	for i, field := range p.fields {
+		if strings.EqualFold(field.name, name) {
+			p.fields = append(p.fields[:i], p.fields[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Stanza returns the values of the paragraph, as a StanzaReader would have
+// read them.
+func (p *Paragraph) Stanza() Stanza {
+	var stanza Stanza
+	for _, field := range p.fields {
+		stanza.put(field.name, field.value())
+	}
+
+	return stanza
+}
+
+// field returns the field called name, matched case-insensitively, or nil.
+func (p *Paragraph) field(name string) *documentField {
+	for _, field := range p.fields {
+		if strings.EqualFold(field.name, name) {
+			return field
+		}
+	}
+
+	return nil
+}
+
+// value parses the value of the field the way StanzaReader.Next does.
+func (f *documentField) value() string {
+	lines := strings.SplitAfter(f.raw, "\n")
+
+	_, value, _ := strings.Cut(lines[0], ":")
+	value = strings.TrimSpace(value)
+
+	for _, line := range lines[1:] {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		line = strings.TrimRightFunc(line[1:], unicode.IsSpace)
+		if line == "." {
+			line = ""
+		}
+
+		if value == "" {
+			value = line + "\n"
+		} else {
+			if !strings.HasSuffix(value, "\n") {
+				value += "\n"
+			}
+			value += line + "\n"
+		}
+	}
+
+	return value
+}
+
+// eol returns the line ending the field was written with.
+func (f *documentField) eol() string {
+	if line, _, _ := strings.Cut(f.raw, "\n"); strings.HasSuffix(line, "\r") {
+		return "\r\n"
+	}
+
+	return "\n"
+}
+
+// renderField formats a field the way Stanza.WriteTo does, ending its lines
+// with eol. The final line ending is left out unless final is set.
+func renderField(name, value, eol string, final bool) string {
+	rendered := name + ": " + strings.ReplaceAll(fold.Value(value), "\n", eol)
+	if final {
+		rendered += eol
+	}
+
+	return rendered
+}
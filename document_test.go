@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+)
+
+const controlFile = `# Leading comment
+
+Source: hello
+section: devel
+Build-Depends: debhelper-compat (= 13),
+# a comment between continuation lines
+	libfoo-dev,
+Standards-Version:  4.6.0
+
+
+Package: hello
+# a comment ahead of a field
+Architecture: any
+Description: example package
+ Long description.
+ .
+   Indented line.    
+# trailing comment
+`
+
+func TestDocumentRoundTrip(t *testing.T) {
+	inputs := map[string]string{
+		"control":        controlFile,
+		"crlf":           strings.ReplaceAll(controlFile, "\n", "\r\n"),
+		"no final eol":   strings.TrimSuffix(controlFile, "\n"),
+		"trailing blank": controlFile + "\n\n",
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			doc, err := deb822.ParseDocument(strings.NewReader(input))
+			require.NoError(t, err)
+
+			var sb strings.Builder
+			n, err := doc.WriteTo(&sb)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(input)), n)
+			require.Equal(t, input, sb.String())
+		})
+	}
+}
+
+func TestDocumentValues(t *testing.T) {
+	doc, err := deb822.ParseDocument(strings.NewReader(controlFile))
+	require.NoError(t, err)
+	require.Len(t, doc.Paragraphs, 2)
+
+	reader, err := deb822.NewStanzaReader(strings.NewReader(controlFile), nil)
+	require.NoError(t, err)
+	stanzas, err := reader.All()
+	require.NoError(t, err)
+
+	// The comment inside Build-Depends is dropped, as dpkg does.
+	stanzas[0].Values["Build-Depends"] = "debhelper-compat (= 13),\nlibfoo-dev,\n"
+	require.Equal(t, stanzas, doc.Stanzas())
+
+	require.Equal(t, []string{"Source", "section", "Build-Depends", "Standards-Version"}, doc.Paragraphs[0].Names())
+
+	value, found := doc.Paragraphs[0].Get("SECTION")
+	require.True(t, found)
+	require.Equal(t, "devel", value)
+
+	_, found = doc.Paragraphs[1].Get("Source")
+	require.False(t, found)
+}
+
+func TestDocumentEdit(t *testing.T) {
+	for name, eol := range map[string]string{"lf": "\n", "crlf": "\r\n"} {
+		t.Run(name, func(t *testing.T) {
+			input := strings.ReplaceAll(controlFile, "\n", eol)
+
+			doc, err := deb822.ParseDocument(strings.NewReader(input))
+			require.NoError(t, err)
+
+			source := doc.Paragraphs[0]
+			require.NoError(t, source.Set("standards-version", "4.7.0"))
+			require.NoError(t, source.Set("Section", "devel")) // unchanged, so left alone
+			require.NoError(t, source.Set("Rules-Requires-Root", "no"))
+
+			binary := doc.Paragraphs[1]
+			require.True(t, binary.Delete("architecture"))
+			require.False(t, binary.Delete("Architecture"))
+
+			want := strings.NewReplacer(
+				"Standards-Version:  4.6.0\n", "Standards-Version: 4.7.0\nRules-Requires-Root: no\n",
+				"# a comment ahead of a field\nArchitecture: any\n", "",
+			).Replace(controlFile)
+
+			require.Equal(t, strings.ReplaceAll(want, "\n", eol), doc.String())
+		})
+	}
+
+	t.Run("no final eol", func(t *testing.T) {
+		doc, err := deb822.ParseDocument(strings.NewReader("A: 1\nB: 2"))
+		require.NoError(t, err)
+
+		require.NoError(t, doc.Paragraphs[0].Set("B", "3"))
+		require.Equal(t, "A: 1\nB: 3", doc.String())
+
+		require.NoError(t, doc.Paragraphs[0].Set("C", "multi\nline"))
+		require.Equal(t, "A: 1\nB: 3\nC: multi\n line\n", doc.String())
+	})
+}
+
+func TestDocumentDeleteLastField(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		delete  string
+		want    string
+		stanzas int
+	}{
+		{
+			name:    "middle paragraph",
+			input:   "A: 1\n\n# about B\nB: 2\n\nC: 3\n",
+			delete:  "B",
+			want:    "A: 1\n\nC: 3\n",
+			stanzas: 2,
+		},
+		{
+			name:    "first paragraph",
+			input:   "A: 1\n\nB: 2\n",
+			delete:  "A",
+			want:    "B: 2\n",
+			stanzas: 1,
+		},
+		{
+			name:    "last paragraph",
+			input:   "A: 1\n\nB: 2\n",
+			delete:  "B",
+			want:    "A: 1\n",
+			stanzas: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := deb822.ParseDocument(strings.NewReader(tt.input))
+			require.NoError(t, err)
+
+			for _, paragraph := range doc.Paragraphs {
+				paragraph.Delete(tt.delete)
+			}
+
+			require.Equal(t, tt.want, doc.String())
+			require.Len(t, doc.Stanzas(), tt.stanzas)
+
+			reparsed, err := deb822.ParseDocument(strings.NewReader(doc.String()))
+			require.NoError(t, err)
+			require.Equal(t, reparsed.Stanzas(), doc.Stanzas())
+		})
+	}
+}
+
+func TestDocumentSetRejects(t *testing.T) {
+	doc, err := deb822.ParseDocument(strings.NewReader("Package: hello\n"))
+	require.NoError(t, err)
+
+	paragraph := doc.Paragraphs[0]
+	require.ErrorIs(t, paragraph.Set("Bad:Name", "v"), deb822.ErrInvalidFieldName)
+	require.ErrorIs(t, paragraph.Set("Description", "a\n.\nb"), deb822.ErrInvalidFieldValue)
+	require.ErrorIs(t, paragraph.Set("package", "a\n.\nb"), deb822.ErrInvalidFieldValue)
+
+	require.Equal(t, []string{"Package"}, paragraph.Names())
+	require.Equal(t, "Package: hello\n", doc.String())
+}
+
+func TestDocumentErrors(t *testing.T) {
+	_, err := deb822.ParseDocument(strings.NewReader("A: 1\n\n continued\n"))
+	require.ErrorIs(t, err, deb822.ErrUnexpectedContinuation)
+
+	var perr *deb822.ParseError
+	_, err = deb822.ParseDocument(bytes.NewReader([]byte("A: 1\nbogus\n")))
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, 2, perr.Line)
+	require.Equal(t, 1, perr.Stanza)
+}
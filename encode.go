@@ -36,9 +36,12 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"slices"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"oaklab.hu/debian/deb822/internal/fold"
 )
 
 // Marshal is a one-off interface to serialize a single object to a writer.
@@ -65,23 +68,69 @@ func Marshal(writer io.Writer, data any) error {
 // Given a struct (or list of structs), write to the io.Writer stream
 // in the RFC822-alike Debian control-file format
 //
-// In order to Marshal a custom Struct, you are required to implement the
-// Marshallable interface. It's highly encouraged to put this interface on
-// the struct without a pointer receiver, so that pass-by-value works
-// when you call Marshal.
+// Structs are rendered field by field, as their struct tags describe (see
+// TagKey), unless they implement the Marshaler interface. It's highly
+// encouraged to put that interface on the struct without a pointer receiver,
+// so that pass-by-value works when you call Marshal.
 type Encoder struct {
 	writer         io.Writer
+	codecs         *Codecs
+	order          FieldOrder
+	style          fold.Style
 	close          func() error
 	alreadyWritten bool
 }
 
+// Marshaler is implemented by types that render their own stanza, for example
+// to compute a Description-md5 or a Size field on the fly. The Encoder calls
+// MarshalDeb822 in place of walking the fields of such a value.
+//
+// An implementation can still have the struct tags do most of the work by
+// converting the value to a type without the method, and handing that to
+// MarshalStanza:
+//
+//	func (p Package) MarshalDeb822() (deb822.Stanza, error) {
+//		type plain Package
+//		stanza, err := deb822.MarshalStanza(plain(p))
+//		...
+//	}
+//
+// Beware that a struct embedding a type that implements Marshaler implements
+// it too, and renders as the embedded type alone.
+type Marshaler interface {
+	MarshalDeb822() (Stanza, error)
+}
+
+// MarshalStanza renders a struct, or a Marshaler, into a Stanza, as an Encoder
+// configured with opts would.
+func MarshalStanza(v any, opts ...EncoderOption) (Stanza, error) {
+	stanza, err := marshalValue(reflect.ValueOf(v), newEncoderOptions(opts).codecs)
+	if err != nil {
+		return Stanza{}, err
+	}
+
+	return *stanza, nil
+}
+
 // Create a new Encoder, which is configured to write to the given `io.Writer`.
-// Optionally, you can pass in a private key to sign the output with.
-func NewEncoder(writer io.Writer, privateKey *openpgp.Entity) (*Encoder, error) {
+// Optionally, you can pass in a private key to clearsign the output with, and
+// EncoderOptions to choose how it is signed, or by whom else.
+func NewEncoder(writer io.Writer, privateKey *openpgp.Entity, opts ...EncoderOption) (*Encoder, error) {
+	options := newEncoderOptions(opts)
+
+	var privateKeys []*packet.PrivateKey
+	for _, signer := range append([]*openpgp.Entity{privateKey}, options.signers...) {
+		if signer != nil {
+			privateKeys = append(privateKeys, signer.PrivateKey)
+		}
+	}
+
+	config := options.signingConfig()
+
 	var clearsignWriter io.WriteCloser
-	if privateKey != nil {
+	if len(privateKeys) > 0 {
 		var err error
-		clearsignWriter, err = clearsign.Encode(writer, privateKey.PrivateKey, nil)
+		clearsignWriter, err = clearsign.EncodeMulti(writer, privateKeys, config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create clearsign writer: %w", err)
 		}
@@ -89,15 +138,44 @@ func NewEncoder(writer io.Writer, privateKey *openpgp.Entity) (*Encoder, error)
 		writer = clearsignWriter
 	}
 
+	var detachedWriter *detachedSigner
+	if options.signature != nil {
+		if len(privateKeys) == 0 {
+			return nil, errors.New("detached signature requires a private key")
+		}
+
+		var err error
+		detachedWriter, err = newDetachedSigner(options.signature, privateKeys, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create detached signer: %w", err)
+		}
+
+		writers := []io.Writer{writer, detachedWriter}
+		if options.document != nil {
+			writers = append(writers, options.document)
+		}
+
+		writer = io.MultiWriter(writers...)
+	}
+
 	return &Encoder{
 		writer: writer,
+		codecs: options.codecs,
+		order:  options.order,
+		style:  options.style,
 		close: func() error {
-			if privateKey != nil {
+			if clearsignWriter != nil {
 				if err := clearsignWriter.Close(); err != nil {
 					return fmt.Errorf("failed to close clearsign writer: %w", err)
 				}
 			}
 
+			if detachedWriter != nil {
+				if err := detachedWriter.Close(); err != nil {
+					return fmt.Errorf("failed to write detached signature: %w", err)
+				}
+			}
+
 			return nil
 		},
 		alreadyWritten: false,
@@ -117,6 +195,10 @@ func (e *Encoder) Encode(incoming interface{}) error {
 }
 
 func (e *Encoder) encode(data reflect.Value) error {
+	if _, ok := asMarshaler(data); ok {
+		return e.encodeStruct(data)
+	}
+
 	if data.Type().Kind() == reflect.Ptr {
 		return e.encode(data.Elem())
 	}
@@ -140,21 +222,77 @@ func (e *Encoder) encodeSlice(data reflect.Value) error {
 }
 
 func (e *Encoder) encodeStruct(data reflect.Value) error {
+	// Render the struct into a stanza, honouring the debian struct tags
+	// (see TagKey) or its MarshalDeb822 method.
+	stanza, err := marshalValue(data, e.codecs)
+	if err != nil {
+		return err
+	}
+
+	// Sort a copy of the field order: a Marshaler may hand over a stanza it
+	// keeps, whose order is not the Encoder's to change.
+	stanza.Order = slices.Clone(stanza.Order)
+	e.order.Sort(stanza)
+
+	// Check the stanza before separating it from the previous one, so that a
+	// rejected stanza leaves no trace in the output.
+	if err := stanza.validate(); err != nil {
+		return err
+	}
+
 	if e.alreadyWritten {
 		_, err := e.writer.Write([]byte("\n"))
 		if err != nil {
 			return err
 		}
 	}
-
-	// Render the struct into a stanza, honouring the debian struct tags
-	// (see TagKey).
-	stanza, err := marshalStruct(data)
-	if err != nil {
-		return err
-	}
 	e.alreadyWritten = true
 
-	_, err = stanza.WriteTo(e.writer)
+	_, err = stanza.writeTo(e.writer, e.style)
 	return err
 }
+
+// marshalValue renders a value into a Stanza: with its MarshalDeb822 method if
+// it has one, otherwise by walking the fields of the struct it is or points
+// to.
+func marshalValue(data reflect.Value, codecs *Codecs) (*Stanza, error) {
+	if marshaler, ok := asMarshaler(data); ok {
+		stanza, err := marshaler.MarshalDeb822()
+		if err != nil {
+			return nil, err
+		}
+
+		return &stanza, nil
+	}
+
+	if data.Kind() == reflect.Ptr {
+		if data.IsNil() {
+			return nil, errors.New("can't Encode a nil pointer")
+		}
+
+		return marshalValue(data.Elem(), codecs)
+	}
+
+	return marshalStruct(data, codecs)
+}
+
+// asMarshaler returns the Marshaler of a value, preferring the value itself
+// and falling back to its address when that is available. Nil pointers have
+// none.
+func asMarshaler(data reflect.Value) (Marshaler, bool) {
+	if !data.IsValid() || (data.Kind() == reflect.Ptr && data.IsNil()) {
+		return nil, false
+	}
+
+	if marshaler, ok := data.Interface().(Marshaler); ok {
+		return marshaler, true
+	}
+
+	if data.CanAddr() {
+		marshaler, ok := data.Addr().Interface().(Marshaler)
+
+		return marshaler, ok
+	}
+
+	return nil, false
+}
@@ -32,6 +32,8 @@
 package deb822_test
 
 import (
+	"bytes"
+	"crypto"
 	"strings"
 	"testing"
 	"time"
@@ -112,3 +114,355 @@ Dependency: foo, bar [amd64] (>= 2.0) | baz
 		require.Contains(t, signedMessage, "END PGP SIGNATURE")
 	})
 }
+
+func TestEncodeSigningOptions(t *testing.T) {
+	a := TestMarshalStruct{
+		Foo:        "Hello",
+		Version:    version.MustParse("1.0-1"),
+		Dependency: dependency.MustParse("foo, bar (>= 1.0) [amd64] | baz"),
+	}
+
+	oldKey := newTestEntity(t)
+	newKey := newTestEntity(t)
+
+	encode := func(t *testing.T, privateKey *openpgp.Entity, opts ...deb822.EncoderOption) string {
+		t.Helper()
+
+		var sb strings.Builder
+		encoder, err := deb822.NewEncoder(&sb, privateKey, opts...)
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(a))
+		require.NoError(t, encoder.Close())
+
+		return sb.String()
+	}
+
+	t.Run("Reproducible", func(t *testing.T) {
+		at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		first := encode(t, oldKey, deb822.WithSigningTime(at))
+		require.Equal(t, first, encode(t, oldKey, deb822.WithSigningTime(at)))
+		require.NotEqual(t, first, encode(t, oldKey, deb822.WithSigningTime(at.Add(time.Second))))
+
+		reader, err := deb822.NewStanzaReader(strings.NewReader(first), openpgp.EntityList{oldKey})
+		require.NoError(t, err)
+		require.True(t, reader.SignatureReport().CreationTime.Equal(at))
+	})
+
+	t.Run("Digest algorithm", func(t *testing.T) {
+		signed := encode(t, oldKey, deb822.WithSigningConfig(&packet.Config{DefaultHash: crypto.SHA512}))
+		require.Contains(t, signed, "Hash: SHA512")
+
+		reader, err := deb822.NewStanzaReader(strings.NewReader(signed), openpgp.EntityList{oldKey})
+		require.NoError(t, err)
+		require.Equal(t, crypto.SHA512, reader.SignatureReport().Hash)
+	})
+
+	t.Run("Multiple signers", func(t *testing.T) {
+		signed := encode(t, oldKey, deb822.WithSigners(newKey))
+
+		reader, err := deb822.NewStanzaReader(
+			strings.NewReader(signed), openpgp.EntityList{oldKey, newKey},
+			deb822.WithSignaturePolicy(deb822.RequireKeyrings(2, openpgp.EntityList{oldKey}, openpgp.EntityList{newKey})),
+		)
+		require.NoError(t, err)
+		require.Len(t, reader.SignatureReports(), 2)
+	})
+
+	t.Run("Signers only", func(t *testing.T) {
+		signed := encode(t, nil, deb822.WithSigners(newKey))
+
+		reader, err := deb822.NewStanzaReader(strings.NewReader(signed), openpgp.EntityList{newKey})
+		require.NoError(t, err)
+		require.Equal(t, newKey, reader.Signer())
+	})
+}
+
+func TestEncodeDetachedSignature(t *testing.T) {
+	a := TestMarshalStruct{
+		Foo:        "Hello",
+		Version:    version.MustParse("1.0-1"),
+		Dependency: dependency.MustParse("foo, bar (>= 1.0) [amd64] | baz"),
+	}
+
+	oldKey := newTestEntity(t)
+	newKey := newTestEntity(t)
+	keyring := openpgp.EntityList{oldKey, newKey}
+
+	var unsigned strings.Builder
+	require.NoError(t, deb822.Marshal(&unsigned, a))
+
+	var inRelease, release, releaseGPG bytes.Buffer
+	encoder, err := deb822.NewEncoder(&inRelease, oldKey,
+		deb822.WithSigners(newKey),
+		deb822.WithDetachedSignature(&release, &releaseGPG),
+	)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(a))
+	require.NoError(t, encoder.Close())
+
+	require.Equal(t, unsigned.String(), release.String())
+	require.Contains(t, releaseGPG.String(), "-----BEGIN PGP SIGNATURE-----")
+
+	policy := deb822.WithSignaturePolicy(deb822.RequireAllSignatures())
+
+	decoder, err := deb822.NewDetachedDecoder(&release, &releaseGPG, keyring, policy)
+	require.NoError(t, err)
+	require.Len(t, decoder.SignatureReports(), 2)
+
+	var decoded TestMarshalStruct
+	require.NoError(t, decoder.Decode(&decoded))
+	require.Equal(t, a.Foo, decoded.Foo)
+
+	reader, err := deb822.NewStanzaReader(&inRelease, keyring, policy)
+	require.NoError(t, err)
+	require.Len(t, reader.SignatureReports(), 2)
+
+	t.Run("Same digest as the clearsigned output", func(t *testing.T) {
+		// A P-384 key does not accept the default SHA-256.
+		key, err := openpgp.NewEntity("test", "", "", &packet.Config{
+			Algorithm: packet.PubKeyAlgoECDSA,
+			Curve:     packet.CurveNistP384,
+		})
+		require.NoError(t, err)
+
+		for _, config := range []*packet.Config{nil, {DefaultHash: crypto.SHA512}} {
+			var inRelease, release, releaseGPG bytes.Buffer
+			encoder, err := deb822.NewEncoder(&inRelease, key,
+				deb822.WithSigningConfig(config),
+				deb822.WithDetachedSignature(&release, &releaseGPG),
+			)
+			require.NoError(t, err)
+			require.NoError(t, encoder.Encode(a))
+			require.NoError(t, encoder.Close())
+
+			reader, err := deb822.NewStanzaReader(&inRelease, openpgp.EntityList{key})
+			require.NoError(t, err)
+
+			decoder, err := deb822.NewDetachedDecoder(&release, &releaseGPG, openpgp.EntityList{key})
+			require.NoError(t, err)
+
+			require.NotEqual(t, crypto.SHA256, reader.SignatureReport().Hash)
+			require.Equal(t, reader.SignatureReport().Hash, decoder.SignatureReport().Hash)
+		}
+	})
+
+	t.Run("Requires a private key", func(t *testing.T) {
+		_, err := deb822.NewEncoder(&inRelease, nil, deb822.WithDetachedSignature(&release, &releaseGPG))
+		require.Error(t, err)
+	})
+}
+
+func TestEncodeFolding(t *testing.T) {
+	type control struct {
+		Package string   `debian:"Package"`
+		Depends string   `debian:"Depends"`
+		Files   []string `debian:"Files,list=line"`
+	}
+
+	value := control{
+		Package: "hello",
+		Depends: "libc6 (>= 2.34), libgcc-s1 (>= 3.0) [amd64 arm64], libstdc++6 (>= 12)",
+		Files:   []string{"a.dsc", "b.tar.xz"},
+	}
+
+	encode := func(t *testing.T, opts ...deb822.EncoderOption) string {
+		t.Helper()
+
+		var sb strings.Builder
+		encoder, err := deb822.NewEncoder(&sb, nil, opts...)
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(value))
+		require.NoError(t, encoder.Close())
+
+		return sb.String()
+	}
+
+	t.Run("default", func(t *testing.T) {
+		require.Equal(t, "Package: hello\n"+
+			"Depends: libc6 (>= 2.34), libgcc-s1 (>= 3.0) [amd64 arm64], libstdc++6 (>= 12)\n"+
+			"Files: \n a.dsc\n b.tar.xz\n", encode(t))
+	})
+
+	t.Run("empty first line and tabs", func(t *testing.T) {
+		require.Equal(t, "Package: hello\n"+
+			"Depends: libc6 (>= 2.34), libgcc-s1 (>= 3.0) [amd64 arm64], libstdc++6 (>= 12)\n"+
+			"Files:\n\ta.dsc\n\tb.tar.xz\n", encode(t, deb822.WithEmptyFirstLine(), deb822.WithTabContinuations()))
+	})
+
+	t.Run("wrap", func(t *testing.T) {
+		require.Equal(t, "Package: hello\n"+
+			"Depends: libc6 (>= 2.34),\n"+
+			"         libgcc-s1 (>= 3.0) [amd64 arm64],\n"+
+			"         libstdc++6 (>= 12)\n"+
+			"Files: \n a.dsc\n b.tar.xz\n", encode(t, deb822.WithWrapWidth(72)))
+	})
+
+	t.Run("wrap with empty first line", func(t *testing.T) {
+		require.Equal(t, "Package: hello\n"+
+			"Depends:\n libc6 (>= 2.34),\n libgcc-s1 (>= 3.0) [amd64 arm64],\n libstdc++6 (>= 12)\n"+
+			"Files:\n a.dsc\n b.tar.xz\n", encode(t, deb822.WithWrapWidth(72), deb822.WithEmptyFirstLine()))
+	})
+
+	t.Run("wrap leaves other fields alone", func(t *testing.T) {
+		type binary struct {
+			Package     string `debian:"Package"`
+			Maintainer  string `debian:"Maintainer"`
+			Description string `debian:"Description"`
+		}
+
+		var sb strings.Builder
+		encoder, err := deb822.NewEncoder(&sb, nil, deb822.WithWrapWidth(40))
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(binary{
+			Package:     "foo",
+			Maintainer:  "ACME, Inc. <packages@acme.example.com>",
+			Description: "Tool for foo, bar and baz, with extra words",
+		}))
+		require.NoError(t, encoder.Close())
+
+		require.Equal(t, "Package: foo\n"+
+			"Maintainer: ACME, Inc. <packages@acme.example.com>\n"+
+			"Description: Tool for foo, bar and baz, with extra words\n", sb.String())
+	})
+}
+
+func TestEncodeRejects(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		want  error
+	}{
+		{
+			name:  "colon in name",
+			key:   "Key:",
+			value: "value",
+			want:  deb822.ErrInvalidFieldName,
+		},
+		{
+			name:  "space in name",
+			key:   "Some Key",
+			value: "value",
+			want:  deb822.ErrInvalidFieldName,
+		},
+		{
+			name:  "leading hash",
+			key:   "#Key",
+			value: "value",
+			want:  deb822.ErrInvalidFieldName,
+		},
+		{
+			name:  "empty name",
+			key:   "",
+			value: "value",
+			want:  deb822.ErrInvalidFieldName,
+		},
+		{
+			name:  "dot line",
+			key:   "Description",
+			value: "summary\n.\nbody",
+			want:  deb822.ErrInvalidFieldValue,
+		},
+		{
+			name:  "dot line with trailing space",
+			key:   "Description",
+			value: "summary\n. \nbody",
+			want:  deb822.ErrInvalidFieldValue,
+		},
+		{
+			name:  "leading whitespace",
+			key:   "Description",
+			value: "  lead",
+			want:  deb822.ErrInvalidFieldValue,
+		},
+		{
+			name:  "trailing whitespace on the first line",
+			key:   "Description",
+			value: "summary \nbody",
+			want:  deb822.ErrInvalidFieldValue,
+		},
+		{
+			name:  "whitespace line",
+			key:   "Description",
+			value: "a\n  \nb",
+			want:  deb822.ErrInvalidFieldValue,
+		},
+		{
+			name:  "trailing whitespace on a later line",
+			key:   "Description",
+			value: "a\nb  ",
+			want:  deb822.ErrInvalidFieldValue,
+		},
+		{
+			name:  "empty lines only",
+			key:   "Files",
+			value: "\n\n",
+			want:  deb822.ErrInvalidFieldValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stanza := deb822.Stanza{Values: map[string]string{}}
+			stanza.Set("Package", "hello")
+			stanza.Set(tt.key, tt.value)
+
+			var sb strings.Builder
+			_, err := stanza.WriteTo(&sb)
+			require.ErrorIs(t, err, tt.want)
+			require.Empty(t, sb.String())
+		})
+	}
+
+	t.Run("encoder", func(t *testing.T) {
+		type badName struct {
+			Key string `debian:"Some Key"`
+		}
+
+		type badValue struct {
+			Description string `debian:"Description"`
+		}
+
+		var sb strings.Builder
+		encoder, err := deb822.NewEncoder(&sb, nil)
+		require.NoError(t, err)
+		require.ErrorIs(t, encoder.Encode(badName{Key: "value"}), deb822.ErrInvalidFieldName)
+		require.ErrorIs(t, encoder.Encode(badValue{Description: "summary\n.\nbody"}), deb822.ErrInvalidFieldValue)
+		require.NoError(t, encoder.Close())
+		require.Empty(t, sb.String())
+	})
+
+	t.Run("round trips what it accepts", func(t *testing.T) {
+		for _, value := range []string{
+			"summary\n\n\nbody\n.dotted\n .",
+			"a\n\nb",
+			"a\nb\n",
+			"a\n\n",
+		} {
+			stanza := deb822.Stanza{Values: map[string]string{}}
+			stanza.Set("Description", value)
+
+			var sb strings.Builder
+			_, err := stanza.WriteTo(&sb)
+			require.NoError(t, err)
+
+			reader, err := deb822.NewStanzaReader(strings.NewReader(sb.String()), nil)
+			require.NoError(t, err)
+
+			decoded, err := reader.Next()
+			require.NoError(t, err)
+			require.Equal(t, strings.TrimSuffix(value, "\n")+"\n", decoded.Values["Description"], "%q", value)
+		}
+	})
+
+	t.Run("accepts a list on lines of its own", func(t *testing.T) {
+		stanza := deb822.Stanza{Values: map[string]string{}}
+		stanza.Set("Files", "\na.dsc\nb.tar.xz")
+
+		var sb strings.Builder
+		_, err := stanza.WriteTo(&sb)
+		require.NoError(t, err)
+		require.Equal(t, "Files: \n a.dsc\n b.tar.xz\n", sb.String())
+	})
+}
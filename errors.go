@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError locates an error in the input of a StanzaReader or Decoder. It
+// wraps the underlying error, so the sentinel errors such as ErrDuplicateField
+// can still be tested for with errors.Is, and is retrieved with errors.As:
+//
+//	var perr *deb822.ParseError
+//	if errors.As(err, &perr) {
+//		log.Printf("stanza %d starting on line %d", perr.Stanza, perr.Line)
+//	}
+type ParseError struct {
+	// Line is the 1-based line number of the offending line in the input,
+	// armor headers of clearsigned input included, or 0 if unknown.
+	Line int
+	// Stanza is the 1-based index of the offending stanza in the input, or 0
+	// if unknown.
+	Stanza int
+	// Field is the name of the offending field, if there is one.
+	Field string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	var sb strings.Builder
+
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, "line %d: ", e.Line)
+	}
+
+	if e.Stanza > 0 {
+		fmt.Fprintf(&sb, "stanza %d: ", e.Stanza)
+	}
+
+	if e.Field != "" {
+		fmt.Fprintf(&sb, "field %q: ", e.Field)
+	}
+
+	sb.WriteString(e.Err.Error())
+
+	return sb.String()
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+)
+
+func TestParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		line   int
+		stanza int
+		field  string
+		want   error
+	}{
+		{
+			name:   "duplicate field",
+			input:  "A: 1\n\nB: 2\nC: 3\nb: 4\n",
+			line:   5,
+			stanza: 2,
+			field:  "b",
+			want:   deb822.ErrDuplicateField,
+		},
+		{
+			name:   "invalid field name",
+			input:  "\n\nA: 1\n-B: 2\n",
+			line:   4,
+			stanza: 1,
+			field:  "-B",
+			want:   deb822.ErrInvalidFieldName,
+		},
+		{
+			name:   "comment",
+			input:  "A: 1\n\n\nB: 2\n\nC: 3\n# comment\n",
+			line:   7,
+			stanza: 3,
+			want:   deb822.ErrCommentNotAllowed,
+		},
+		{
+			name:   "continuation",
+			input:  "A: 1\n\n continued\n",
+			line:   3,
+			stanza: 2,
+			want:   deb822.ErrUnexpectedContinuation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := deb822.NewStanzaReader(strings.NewReader(tt.input), nil, deb822.WithStrict())
+			require.NoError(t, err)
+
+			_, err = reader.All()
+			require.ErrorIs(t, err, tt.want)
+
+			var perr *deb822.ParseError
+			require.True(t, errors.As(err, &perr))
+			require.Equal(t, tt.line, perr.Line)
+			require.Equal(t, tt.stanza, perr.Stanza)
+			require.Equal(t, tt.field, perr.Field)
+		})
+	}
+}
+
+func TestParseErrorDecode(t *testing.T) {
+	input := `Value: foo
+Version: 1.0
+
+Value: bar
+Depends: foo
+Version: -1
+`
+
+	var values []TestStruct
+	err := deb822.Unmarshal([]byte(input), &values)
+
+	var perr *deb822.ParseError
+	require.True(t, errors.As(err, &perr))
+	require.Equal(t, 6, perr.Line)
+	require.Equal(t, 2, perr.Stanza)
+	require.Equal(t, "Version", perr.Field)
+	require.ErrorContains(t, err, `line 6: stanza 2: field "Version": `)
+}
+
+func TestParseErrorClearsigned(t *testing.T) {
+	entity := newTestEntity(t)
+
+	var signed bytes.Buffer
+	w, err := clearsign.Encode(&signed, entity.PrivateKey, nil)
+	require.NoError(t, err)
+	_, err = w.Write([]byte("A: 1\n\nbogus\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	before, _, found := strings.Cut(signed.String(), "bogus")
+	require.True(t, found)
+	line := strings.Count(before, "\n") + 1
+
+	for _, opts := range [][]deb822.ReaderOption{nil, {deb822.WithStreamingVerification()}} {
+		reader, err := deb822.NewStanzaReader(bytes.NewReader(signed.Bytes()), openpgp.EntityList{entity}, opts...)
+		require.NoError(t, err)
+
+		_, err = reader.All()
+
+		var perr *deb822.ParseError
+		require.True(t, errors.As(err, &perr))
+		require.Equal(t, line, perr.Line)
+		require.Equal(t, 2, perr.Stanza)
+	}
+}
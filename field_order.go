@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"slices"
+	"strings"
+)
+
+// FieldOrder is the canonical order of the fields of a type of document, the
+// order dpkg writes them in. An Encoder sorts the fields of every stanza into
+// it under WithFieldOrder.
+//
+// The fields dpkg knows for the type of document come first, in its order.
+// The others follow, sorted by name the way dpkg sorts them: byte by byte, so
+// that upper case sorts before lower case. Known field names are matched case
+// insensitively.
+type FieldOrder int
+
+// The canonical field orders. They follow the field_ordered_list of dpkg's
+// Dpkg::Control::FieldsCore for the matching control type.
+const (
+	// ControlOrder is the order of the control file of a binary package,
+	// DEBIAN/control (CTRL_PKG_DEB).
+	ControlOrder FieldOrder = iota + 1
+	// PackagesOrder is the order of a Packages index (CTRL_INDEX_PKG).
+	PackagesOrder
+	// SourcesOrder is the order of a Sources index (CTRL_INDEX_SRC).
+	SourcesOrder
+	// DscOrder is the order of a source package control file, a .dsc
+	// (CTRL_PKG_SRC).
+	DscOrder
+	// ChangesOrder is the order of a .changes file (CTRL_FILE_CHANGES).
+	ChangesOrder
+	// ReleaseOrder is the order of a Release file (CTRL_REPO_RELEASE).
+	ReleaseOrder
+)
+
+// fieldOrderNames lists the fields of every FieldOrder, in order.
+var fieldOrderNames = map[FieldOrder][]string{
+	ControlOrder: {
+		"Package", "Package-Type", "Source", "Version", "Kernel-Version",
+		"Built-For-Profiles", "Auto-Built-Package", "Architecture",
+		"Subarchitecture", "Installer-Menu-Item", "Build-Essential",
+		"Essential", "Protected", "Origin", "Bugs", "Maintainer",
+		"Installed-Size", "Pre-Depends", "Depends", "Recommends", "Suggests",
+		"Enhances", "Conflicts", "Breaks", "Replaces", "Provides",
+		"Built-Using", "Static-Built-Using", "Section", "Priority",
+		"Multi-Arch", "Homepage", "Description", "Tag", "Task",
+	},
+	PackagesOrder: {
+		"Package", "Package-Type", "Source", "Version", "Kernel-Version",
+		"Built-For-Profiles", "Auto-Built-Package", "Architecture",
+		"Subarchitecture", "Installer-Menu-Item", "Build-Essential",
+		"Essential", "Protected", "Origin", "Bugs", "Maintainer",
+		"Installed-Size", "Pre-Depends", "Depends", "Recommends", "Suggests",
+		"Enhances", "Conflicts", "Breaks", "Replaces", "Provides",
+		"Built-Using", "Static-Built-Using", "Filename", "Size", "MD5sum",
+		"SHA1", "SHA256", "Section", "Priority", "Multi-Arch", "Homepage",
+		"Description", "Tag", "Task",
+	},
+	SourcesOrder: {
+		"Format", "Package", "Binary", "Architecture", "Version", "Priority",
+		"Section", "Origin", "Maintainer", "Uploaders", "Homepage",
+		"Description", "Standards-Version", "Vcs-Browser", "Vcs-Arch",
+		"Vcs-Bzr", "Vcs-Cvs", "Vcs-Darcs", "Vcs-Git", "Vcs-Hg", "Vcs-Mtn",
+		"Vcs-Svn", "Testsuite", "Testsuite-Triggers", "Build-Depends",
+		"Build-Depends-Arch", "Build-Depends-Indep", "Build-Conflicts",
+		"Build-Conflicts-Arch", "Build-Conflicts-Indep", "Package-List",
+		"Directory", "Checksums-Md5", "Checksums-Sha1", "Checksums-Sha256",
+		"Files",
+	},
+	DscOrder: {
+		"Format", "Source", "Binary", "Architecture", "Version", "Origin",
+		"Maintainer", "Uploaders", "Homepage", "Description",
+		"Standards-Version", "Vcs-Browser", "Vcs-Arch", "Vcs-Bzr", "Vcs-Cvs",
+		"Vcs-Darcs", "Vcs-Git", "Vcs-Hg", "Vcs-Mtn", "Vcs-Svn", "Testsuite",
+		"Testsuite-Triggers", "Build-Depends", "Build-Depends-Arch",
+		"Build-Depends-Indep", "Build-Conflicts", "Build-Conflicts-Arch",
+		"Build-Conflicts-Indep", "Package-List", "Checksums-Md5",
+		"Checksums-Sha1", "Checksums-Sha256", "Files",
+	},
+	ChangesOrder: {
+		"Format", "Date", "Source", "Binary", "Binary-Only",
+		"Built-For-Profiles", "Architecture", "Version", "Distribution",
+		"Urgency", "Maintainer", "Changed-By", "Description", "Closes",
+		"Changes", "Checksums-Md5", "Checksums-Sha1", "Checksums-Sha256",
+		"Files",
+	},
+	ReleaseOrder: {
+		"Origin", "Label", "Suite", "Version", "Codename", "Changelogs",
+		"Date", "Valid-Until", "NotAutomatic", "ButAutomaticUpgrades",
+		"Acquire-By-Hash", "No-Support-for-Architecture-all",
+		"Architectures", "Components", "Description", "MD5sum", "SHA1",
+		"SHA256",
+	},
+}
+
+// fieldOrderRanks maps the lower cased fields of every FieldOrder onto their
+// position in it.
+var fieldOrderRanks = func() map[FieldOrder]map[string]int {
+	ranks := make(map[FieldOrder]map[string]int, len(fieldOrderNames))
+	for order, names := range fieldOrderNames {
+		ranks[order] = make(map[string]int, len(names))
+		for i, name := range names {
+			ranks[order][strings.ToLower(name)] = i
+		}
+	}
+
+	return ranks
+}()
+
+// Sort sorts the fields of a stanza into the order. The zero FieldOrder, like
+// any other unknown one, leaves the stanza as it is.
+func (o FieldOrder) Sort(stanza *Stanza) {
+	ranks, found := fieldOrderRanks[o]
+	if !found {
+		return
+	}
+
+	slices.SortStableFunc(stanza.Order, func(a, b string) int {
+		rankA, knownA := ranks[strings.ToLower(a)]
+		rankB, knownB := ranks[strings.ToLower(b)]
+
+		switch {
+		case knownA && knownB:
+			return rankA - rankB
+		case knownA:
+			return -1
+		case knownB:
+			return 1
+		}
+
+		return strings.Compare(a, b)
+	})
+}
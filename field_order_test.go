@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+)
+
+func TestFieldOrder(t *testing.T) {
+	t.Run("matches dpkg-source", func(t *testing.T) {
+		f, err := os.Open("testdata/0ad_0.0.26-3.dsc")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, f.Close())
+		})
+
+		pubKeyFile, err := os.Open("testdata/d53a815a3cb7659af882e3958eedcc1baa1f32ff.asc")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, pubKeyFile.Close())
+		})
+
+		keyring, err := openpgp.ReadArmoredKeyRing(pubKeyFile)
+		require.NoError(t, err)
+
+		reader, err := deb822.NewStanzaReader(f, keyring)
+		require.NoError(t, err)
+
+		stanza, err := reader.Next()
+		require.NoError(t, err)
+
+		want := slices.Clone(stanza.Order)
+		slices.Reverse(stanza.Order)
+
+		deb822.DscOrder.Sort(stanza)
+		require.Equal(t, want, stanza.Order)
+	})
+
+	t.Run("unknown fields", func(t *testing.T) {
+		stanza := deb822.Stanza{Order: []string{"x-alpha", "Description", "X-Zeta", "Package", "Ruby-Versions"}}
+
+		deb822.ControlOrder.Sort(&stanza)
+		require.Equal(t, []string{"Package", "Description", "Ruby-Versions", "X-Zeta", "x-alpha"}, stanza.Order)
+	})
+
+	t.Run("encoder", func(t *testing.T) {
+		type entry struct {
+			Filename string `debian:"Filename"`
+			Version  string `debian:"Version"`
+			MD5Sum   string `debian:"md5sum"`
+			Package  string `debian:"Package"`
+			SHA512   string `debian:"SHA512"`
+		}
+
+		var sb strings.Builder
+		encoder, err := deb822.NewEncoder(&sb, nil, deb822.WithFieldOrder(deb822.PackagesOrder))
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(entry{
+			Filename: "pool/main/h/hello/hello_2.10-3_amd64.deb",
+			Version:  "2.10-3",
+			MD5Sum:   "d41d8cd98f00b204e9800998ecf8427e",
+			Package:  "hello",
+			SHA512:   "cf83e135",
+		}))
+		require.NoError(t, encoder.Close())
+
+		require.Equal(t, `Package: hello
+Version: 2.10-3
+Filename: pool/main/h/hello/hello_2.10-3_amd64.deb
+md5sum: d41d8cd98f00b204e9800998ecf8427e
+SHA512: cf83e135
+`, sb.String())
+	})
+}
@@ -0,0 +1,171 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// An Index locates the stanzas of a file by the value of one of their fields,
+// such as the Package field of a Packages or Sources index, to decode them one
+// at a time on demand:
+//
+//	index, err := deb822.NewIndex(f, "Package")
+//	...
+//	packages, err := deb822.Lookup[types.Package](index, "hello")
+//
+// Only the byte range of every stanza is kept in memory; stanzas are read
+// back from the file when they are looked up. The file must therefore not
+// change while the Index is in use. Being an io.ReaderAt, it cannot be
+// compressed either; a memory-mapped file suits it well.
+//
+// An Index is safe for concurrent use, as long as its io.ReaderAt is.
+type Index struct {
+	reader  io.ReaderAt
+	opts    readerOptions
+	entries map[string][]indexEntry
+}
+
+// indexEntry locates a stanza in the file of an Index, by its byte range and,
+// to locate the errors decoding it returns, its first line and its index.
+type indexEntry struct {
+	offset, length int64
+	line           int
+	stanza         int
+}
+
+// NewIndex reads the plain, unsigned stanzas of reader in a single pass, and
+// indexes them by the value of the named field, matched case-insensitively.
+// Stanzas without the field are left out. Stanzas sharing a value, such as
+// the versions of a package in a Packages index, are kept in input order.
+//
+// The parser options among opts, such as WithStrict, apply to the pass, and
+// all of opts to the decoding of the stanzas looked up.
+func NewIndex(reader io.ReaderAt, field string, opts ...ReaderOption) (*Index, error) {
+	index := Index{
+		reader:  reader,
+		opts:    newReaderOptions(opts),
+		entries: make(map[string][]indexEntry),
+	}
+
+	scanner := newScanner(bufio.NewReader(io.NewSectionReader(reader, 0, math.MaxInt64)), index.opts, 0)
+	name := []byte(field)
+
+	for scanner.Scan() {
+		for i := range scanner.Len() {
+			fieldName, value := scanner.Field(i)
+			if !bytes.EqualFold(fieldName, name) {
+				continue
+			}
+
+			key := string(value)
+			index.entries[key] = append(index.entries[key], indexEntry{
+				offset: scanner.start,
+				length: scanner.end - scanner.start,
+				line:   scanner.startLine,
+				stanza: scanner.stanzas,
+			})
+
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &index, nil
+}
+
+// Len returns the number of distinct values the stanzas are indexed by.
+func (ix *Index) Len() int {
+	return len(ix.entries)
+}
+
+// Keys returns the values the stanzas are indexed by, sorted.
+func (ix *Index) Keys() []string {
+	return slices.Sorted(maps.Keys(ix.entries))
+}
+
+// Stanzas reads back the stanzas indexed by key, in input order. There are
+// none for a key the Index does not know.
+func (ix *Index) Stanzas(key string) ([]Stanza, error) {
+	entries := ix.entries[key]
+	stanzas := make([]Stanza, 0, len(entries))
+
+	for _, entry := range entries {
+		stanza, _, err := ix.read(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		stanzas = append(stanzas, stanza)
+	}
+
+	return stanzas, nil
+}
+
+// Lookup decodes the stanzas of an Index indexed by key into values of the
+// struct type T, in input order. There are none for a key the Index does not
+// know.
+func Lookup[T any](index *Index, key string) ([]T, error) {
+	entries := index.entries[key]
+	items := make([]T, 0, len(entries))
+
+	for _, entry := range entries {
+		stanza, fieldLines, err := index.read(entry)
+		if err != nil {
+			return nil, err
+		}
+
+		var item T
+		if err := decodeStruct(stanza, reflect.ValueOf(&item), index.opts); err != nil {
+			return nil, locate(err, entry.stanza, fieldLines)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// read reads back the stanza an entry locates, along with the lines its
+// fields start on.
+func (ix *Index) read(entry indexEntry) (Stanza, map[string]int, error) {
+	section := io.NewSectionReader(ix.reader, entry.offset, entry.length)
+
+	scanner := newScanner(bufio.NewReader(section), ix.opts, entry.line-1)
+	scanner.stanzas = entry.stanza - 1
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return Stanza{}, nil, err
+		}
+
+		return Stanza{}, nil, &ParseError{Line: entry.line, Stanza: entry.stanza, Err: fmt.Errorf("indexed stanza: %w", io.ErrUnexpectedEOF)}
+	}
+
+	var stanza Stanza
+	scanner.Stanza(&stanza)
+
+	fieldLines := make(map[string]int, len(stanza.Order))
+	for i, key := range stanza.Order {
+		fieldLines[key] = scanner.fields[i].line
+	}
+
+	return stanza, fieldLines, nil
+}
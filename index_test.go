@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+	"oaklab.hu/debian/deb822/types"
+)
+
+func TestIndex(t *testing.T) {
+	input := `Package: hello
+Version: 2.10-3
+Architecture: amd64
+Description: example package based on GNU hello
+ The GNU hello program produces a familiar, friendly greeting.
+
+# not indexed: no Package field
+Origin: Debian
+
+Package: hello
+Version: 2.10-2
+Architecture: i386
+
+Package: broken
+Version: :1
+Architecture: all
+
+Package: sl
+Version: 5.02-1
+Architecture: amd64
+`
+
+	path := filepath.Join(t.TempDir(), "Packages")
+	require.NoError(t, os.WriteFile(path, []byte(input), 0o644))
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, f.Close())
+	})
+
+	index, err := deb822.NewIndex(f, "package")
+	require.NoError(t, err)
+	require.Equal(t, 3, index.Len())
+	require.Equal(t, []string{"broken", "hello", "sl"}, index.Keys())
+
+	t.Run("lookup", func(t *testing.T) {
+		packages, err := deb822.Lookup[types.Package](index, "hello")
+		require.NoError(t, err)
+		require.Len(t, packages, 2)
+		require.Equal(t, "2.10-3", packages[0].Version.String())
+		require.Equal(t, "i386", packages[1].Architecture.String())
+		require.Contains(t, packages[0].Description, "friendly greeting")
+
+		packages, err = deb822.Lookup[types.Package](index, "sl")
+		require.NoError(t, err)
+		require.Len(t, packages, 1)
+		require.Equal(t, "sl", packages[0].Name)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		packages, err := deb822.Lookup[types.Package](index, "missing")
+		require.NoError(t, err)
+		require.Empty(t, packages)
+	})
+
+	t.Run("stanzas", func(t *testing.T) {
+		stanzas, err := index.Stanzas("sl")
+		require.NoError(t, err)
+		require.Len(t, stanzas, 1)
+		require.Equal(t, []string{"Package", "Version", "Architecture"}, stanzas[0].Order)
+	})
+
+	t.Run("error position", func(t *testing.T) {
+		_, err := deb822.Lookup[types.Package](index, "broken")
+		require.Error(t, err)
+
+		var perr *deb822.ParseError
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, 4, perr.Stanza)
+		require.Equal(t, 15, perr.Line)
+		require.Equal(t, "Version", perr.Field)
+	})
+
+	t.Run("in memory", func(t *testing.T) {
+		index, err := deb822.NewIndex(strings.NewReader(input), "Package")
+		require.NoError(t, err)
+
+		packages, err := deb822.Lookup[types.Package](index, "hello")
+		require.NoError(t, err)
+		require.Len(t, packages, 2)
+	})
+}
@@ -20,8 +20,135 @@ import "strings"
 //
 // It is the inverse of the unfolding the stanza reader performs on decode.
 func Value(value string) string {
-	value = strings.ReplaceAll(value, "\n", "\n ")
-	value = strings.ReplaceAll(value, "\n \n", "\n .\n")
+	return indent(value, " ")
+}
+
+// Style lays out a field as it is written. The zero Style writes a field the
+// way Value folds it.
+type Style struct {
+	// EmptyFirstLine leaves nothing after the colon of a field whose value
+	// starts with an empty line, as dpkg writes the Files of a .dsc, rather
+	// than a trailing space. Lists that are wrapped start on an empty line
+	// too.
+	EmptyFirstLine bool
+
+	// Indent prefixes every continuation line; a single space when empty.
+	Indent string
+
+	// Width, when positive, wraps the value of a list field whose field line
+	// would be longer, the way wrap-and-sort does: one entry per line, the
+	// first next to the field name and the others aligned under it, or all on
+	// lines of their own under EmptyFirstLine. The list fields are the
+	// relationship fields, Built-Using, Uploaders and Binary; the values of
+	// others, such as a Description or a Maintainer, are left alone.
+	Width int
+}
+
+// Field renders a field, its name and folded value, ending in a newline.
+func (s Style) Field(name, value string) string {
+	prefix := s.Indent
+	if prefix == "" {
+		prefix = " "
+	}
+
+	if s.Width > 0 && isList(name) && !strings.Contains(value, "\n") && len(name)+2+len(value) > s.Width {
+		if entries := splitList(value); len(entries) > 1 {
+			value = strings.Join(entries, ",\n")
+
+			if s.EmptyFirstLine {
+				value = "\n" + value
+			} else {
+				prefix = strings.Repeat(" ", len(name)+2)
+			}
+		}
+	}
+
+	value = indent(value, prefix)
+	if s.EmptyFirstLine && strings.HasPrefix(value, "\n") {
+		return name + ":" + value + "\n"
+	}
+
+	return name + ": " + value + "\n"
+}
+
+// listFields are the comma separated list fields a Style wraps, by lower case
+// name.
+var listFields = map[string]struct{}{
+	"depends":               {},
+	"pre-depends":           {},
+	"recommends":            {},
+	"suggests":              {},
+	"enhances":              {},
+	"breaks":                {},
+	"conflicts":             {},
+	"replaces":              {},
+	"provides":              {},
+	"build-depends":         {},
+	"build-depends-indep":   {},
+	"build-depends-arch":    {},
+	"build-conflicts":       {},
+	"build-conflicts-indep": {},
+	"build-conflicts-arch":  {},
+	"built-using":           {},
+	"uploaders":             {},
+	"binary":                {},
+}
+
+// isList reports whether the field of that name is one a Style wraps. Field
+// names are case-insensitive.
+func isList(name string) bool {
+	_, found := listFields[strings.ToLower(name)]
+
+	return found
+}
+
+// indent folds a value as Value does, prefixing continuation lines with
+// prefix.
+func indent(value, prefix string) string {
+	lines := strings.Split(value, "\n")
+	for i := 1; i < len(lines); i++ {
+		// What follows the final newline is the padding of the fold, not a
+		// blank line.
+		if i < len(lines)-1 && strings.TrimSpace(lines[i]) == "" {
+			lines[i] = "."
+		}
+
+		lines[i] = prefix + lines[i]
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n "+prefix)
+}
+
+// splitList splits a comma separated list into its trimmed, non-empty
+// entries. Commas inside parentheses or brackets do not split.
+func splitList(value string) []string {
+	var (
+		entries []string
+		depth   int
+		start   int
+	)
+
+	add := func(entry string) {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	for i, c := range value {
+		switch c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				add(value[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	add(value[start:])
 
-	return strings.TrimRight(value, "\n ")
+	return entries
 }
@@ -42,6 +42,11 @@ func TestValue(t *testing.T) {
 			value:    "short\nfirst\n\nsecond",
 			expected: "short\n first\n .\n second",
 		},
+		{
+			name:     "consecutive blank lines",
+			value:    "short\nfirst\n\n\nsecond",
+			expected: "short\n first\n .\n .\n second",
+		},
 		{
 			name:     "trailing newline is trimmed",
 			value:    "short\nfirst\n",
@@ -60,3 +65,78 @@ func TestValue(t *testing.T) {
 		})
 	}
 }
+
+func TestStyle(t *testing.T) {
+	depends := "libc6 (>= 2.34), libfoo1 (>= 1.2), libbar2 [amd64 arm64], ${misc:Depends},"
+
+	tests := []struct {
+		name     string
+		style    fold.Style
+		field    string
+		value    string
+		expected string
+	}{
+		{
+			name:     "zero style",
+			value:    "\na\nb",
+			expected: "Files: \n a\n b\n",
+		},
+		{
+			name:     "empty first line",
+			style:    fold.Style{EmptyFirstLine: true},
+			value:    "\na\nb",
+			expected: "Files:\n a\n b\n",
+		},
+		{
+			name:     "tab continuations",
+			style:    fold.Style{Indent: "\t"},
+			value:    "short\nfirst\n\nsecond",
+			expected: "Files: short\n\tfirst\n\t.\n\tsecond\n",
+		},
+		{
+			name:     "short list is not wrapped",
+			style:    fold.Style{Width: 79},
+			field:    "Depends",
+			value:    "a, b",
+			expected: "Depends: a, b\n",
+		},
+		{
+			name:     "long list is wrapped and aligned",
+			style:    fold.Style{Width: 40},
+			field:    "Depends",
+			value:    depends,
+			expected: "Depends: libc6 (>= 2.34),\n         libfoo1 (>= 1.2),\n         libbar2 [amd64 arm64],\n         ${misc:Depends}\n",
+		},
+		{
+			name:     "long list on lines of its own",
+			style:    fold.Style{Width: 40, EmptyFirstLine: true},
+			field:    "build-depends",
+			value:    depends,
+			expected: "build-depends:\n libc6 (>= 2.34),\n libfoo1 (>= 1.2),\n libbar2 [amd64 arm64],\n ${misc:Depends}\n",
+		},
+		{
+			name:     "long value that is no list",
+			style:    fold.Style{Width: 10},
+			value:    "https://example.org/a/long/path",
+			expected: "Files: https://example.org/a/long/path\n",
+		},
+		{
+			name:     "long value of a field that is no list",
+			style:    fold.Style{Width: 40},
+			field:    "Maintainer",
+			value:    "ACME, Inc. <packages@acme.example.com>",
+			expected: "Maintainer: ACME, Inc. <packages@acme.example.com>\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			field := test.field
+			if field == "" {
+				field = "Files"
+			}
+
+			require.Equal(t, test.expected, test.style.Field(field, test.value))
+		})
+	}
+}
@@ -0,0 +1,251 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Delimiters accepted by the list option. They match the types of the list
+// package: CommaDelimited, SpaceDelimited and NewLineDelimited.
+const (
+	listComma = "comma"
+	listSpace = "space"
+	listLine  = "line"
+)
+
+// listSeparators maps each delimiter onto the separator entries are joined
+// with.
+var listSeparators = map[string]string{
+	listComma: ", ",
+	listSpace: " ",
+	listLine:  "\n",
+}
+
+// collectionValue follows the pointers of a struct field down to a plain
+// slice carried under the list option, or to a map with string keys, and
+// reports whether it found one. Types that render themselves as text, such as
+// those of the list package, and types codecs has a codec for are not
+// collections.
+//
+// When alloc is set nil pointers are allocated on the way; otherwise a nil
+// pointer ends the walk, with no collection found.
+func collectionValue(value reflect.Value, list string, alloc bool, codecs *Codecs) (reflect.Value, bool) {
+	t := value.Type()
+	for t.Kind() == reflect.Pointer && !marshalsAsText(t) && !codecs.has(t) {
+		t = t.Elem()
+	}
+
+	if marshalsAsText(t) || codecs.has(t) {
+		return value, false
+	}
+
+	switch {
+	case t.Kind() == reflect.Slice && list != "":
+	case t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+	default:
+		return value, false
+	}
+
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			if !alloc || !value.CanSet() {
+				return value, false
+			}
+
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+
+		value = value.Elem()
+	}
+
+	return value, true
+}
+
+// marshalField renders a struct field as deb822 field text: a collection by
+// marshalList or marshalMap, anything else by marshalFieldText.
+func marshalField(value reflect.Value, field *fieldInfo, codecs *Codecs) (string, bool, error) {
+	if collection, ok := collectionValue(value, field.list, false, codecs); ok {
+		if collection.Kind() == reflect.Map {
+			return marshalMap(collection, field.list, codecs)
+		}
+
+		return marshalList(collection, field.list, codecs)
+	}
+
+	return marshalFieldText(value, codecs)
+}
+
+// unmarshalField parses deb822 field text into a struct field: a collection
+// by unmarshalList or unmarshalMap, anything else by unmarshalFieldText.
+func unmarshalField(value reflect.Value, text string, field *fieldInfo, codecs *Codecs) error {
+	if collection, ok := collectionValue(value, field.list, true, codecs); ok {
+		if collection.Kind() == reflect.Map {
+			return unmarshalMap(collection, text, field.list, codecs)
+		}
+
+		return unmarshalList(collection, text, field.list, codecs)
+	}
+
+	return unmarshalFieldText(value, text, codecs)
+}
+
+// marshalList renders a slice as a delimited list. Entries are rendered by
+// marshalFieldText and joined with ", " for a comma delimited list and " " for
+// a space delimited one. A line delimited list puts each entry on a line of
+// its own, starting on the line after the field name.
+func marshalList(value reflect.Value, list string, codecs *Codecs) (string, bool, error) {
+	separator, found := listSeparators[list]
+	if !found {
+		return "", false, fmt.Errorf("unsupported list delimiter %q", list)
+	}
+
+	if value.Len() == 0 {
+		return "", true, nil
+	}
+
+	entries := make([]string, value.Len())
+	for i := range entries {
+		text, ok, err := marshalFieldText(value.Index(i), codecs)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to marshal entry: %w", err)
+		} else if !ok {
+			return "", false, fmt.Errorf("failed to marshal entry %d: no value", i)
+		}
+
+		entries[i] = text
+	}
+
+	text := strings.Join(entries, separator)
+	if list == listLine {
+		text = "\n" + text
+	}
+
+	return text, true, nil
+}
+
+// unmarshalList parses a delimited list into a slice, replacing its contents.
+// Entries are trimmed of surrounding whitespace, and empty ones are skipped.
+func unmarshalList(value reflect.Value, text, list string, codecs *Codecs) error {
+	var items []string
+
+	switch list {
+	case listComma:
+		items = strings.Split(text, ",")
+	case listSpace:
+		items = strings.Fields(text)
+	case listLine:
+		items = strings.Split(text, "\n")
+	default:
+		return fmt.Errorf("unsupported list delimiter %q", list)
+	}
+
+	slice := reflect.MakeSlice(value.Type(), 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		entry := reflect.New(value.Type().Elem()).Elem()
+		if err := unmarshalFieldText(entry, item, codecs); err != nil {
+			return fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+
+		slice = reflect.Append(slice, entry)
+	}
+
+	value.Set(slice)
+
+	return nil
+}
+
+// marshalMap renders a map as a multiline key-value field: one entry per
+// line, sorted by key, with the key and its value separated by a space. Like
+// a line delimited list, the entries start on the line after the field name.
+func marshalMap(value reflect.Value, list string, codecs *Codecs) (string, bool, error) {
+	if list != "" && list != listLine {
+		return "", false, fmt.Errorf("unsupported list delimiter %q for a map", list)
+	}
+
+	if value.Len() == 0 {
+		return "", true, nil
+	}
+
+	keys := make([]string, 0, value.Len())
+	for _, key := range value.MapKeys() {
+		keys = append(keys, key.String())
+	}
+	slices.Sort(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		if key == "" || strings.IndexFunc(key, unicode.IsSpace) >= 0 {
+			return "", false, fmt.Errorf("invalid map key %q", key)
+		}
+
+		text, _, err := marshalFieldText(value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key())), codecs)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to marshal entry %q: %w", key, err)
+		} else if strings.Contains(text, "\n") {
+			return "", false, fmt.Errorf("failed to marshal entry %q: value spans several lines", key)
+		}
+
+		sb.WriteString("\n" + key)
+		if text != "" {
+			sb.WriteString(" " + text)
+		}
+	}
+
+	return sb.String(), true, nil
+}
+
+// unmarshalMap parses a multiline key-value field into a map, replacing its
+// contents. Each non-empty line is an entry: its first word is the key, the
+// rest of the line the value.
+func unmarshalMap(value reflect.Value, text, list string, codecs *Codecs) error {
+	if list != "" && list != listLine {
+		return fmt.Errorf("unsupported list delimiter %q for a map", list)
+	}
+
+	entries := reflect.MakeMap(value.Type())
+
+	for line := range strings.SplitSeq(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		key, rest := line, ""
+		if i := strings.IndexFunc(line, unicode.IsSpace); i >= 0 {
+			key, rest = line[:i], strings.TrimSpace(line[i:])
+		}
+
+		mapKey := reflect.ValueOf(key).Convert(value.Type().Key())
+		if entries.MapIndex(mapKey).IsValid() {
+			return fmt.Errorf("duplicate map key %q", key)
+		}
+
+		entry := reflect.New(value.Type().Elem()).Elem()
+		if err := unmarshalFieldText(entry, rest, codecs); err != nil {
+			return fmt.Errorf("failed to unmarshal entry %q: %w", key, err)
+		}
+
+		entries.SetMapIndex(mapKey, entry)
+	}
+
+	value.Set(entries)
+
+	return nil
+}
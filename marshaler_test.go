@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+)
+
+var errDescriptionMismatch = errors.New("Description-md5 does not match Description")
+
+// described derives its Description-md5 on encode and checks it on decode,
+// leaving the rest to its struct tags.
+type described struct {
+	Package        string `debian:"Package"`
+	Description    string `debian:"Description"`
+	DescriptionMD5 string `debian:"Description-md5,omitempty"`
+}
+
+func descriptionMD5(description string) string {
+	sum := md5.Sum([]byte(description + "\n"))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func (d described) MarshalDeb822() (deb822.Stanza, error) {
+	type plain described
+
+	p := plain(d)
+	p.DescriptionMD5 = descriptionMD5(d.Description)
+
+	return deb822.MarshalStanza(p)
+}
+
+func (d *described) UnmarshalDeb822(stanza deb822.Stanza) error {
+	type plain described
+
+	if err := deb822.UnmarshalStanza(stanza, (*plain)(d)); err != nil {
+		return err
+	}
+
+	if d.DescriptionMD5 != "" && d.DescriptionMD5 != descriptionMD5(d.Description) {
+		return errDescriptionMismatch
+	}
+
+	return nil
+}
+
+func TestMarshaler(t *testing.T) {
+	encoded := "Package: foo\nDescription: the foo tool\nDescription-md5: " + descriptionMD5("the foo tool") + "\n"
+
+	t.Run("value", func(t *testing.T) {
+		require.Equal(t, encoded, marshalToString(t, described{Package: "foo", Description: "the foo tool"}))
+	})
+
+	t.Run("slice of pointers", func(t *testing.T) {
+		require.Equal(t, encoded+"\n"+encoded, marshalToString(t, []*described{
+			{Package: "foo", Description: "the foo tool"},
+			{Package: "foo", Description: "the foo tool"},
+		}))
+	})
+
+	t.Run("MarshalStanza", func(t *testing.T) {
+		stanza, err := deb822.MarshalStanza(&described{Package: "foo", Description: "the foo tool"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"Package", "Description", "Description-md5"}, stanza.Order)
+	})
+
+	t.Run("field order leaves the stanza alone", func(t *testing.T) {
+		kept := stored{stanza: deb822.Stanza{
+			Values: map[string]string{"Version": "1.0", "Package": "foo"},
+			Order:  []string{"Version", "Package"},
+		}}
+
+		var sb strings.Builder
+		encoder, err := deb822.NewEncoder(&sb, nil, deb822.WithFieldOrder(deb822.PackagesOrder))
+		require.NoError(t, err)
+		require.NoError(t, encoder.Encode(kept))
+		require.NoError(t, encoder.Close())
+
+		require.Equal(t, "Package: foo\nVersion: 1.0\n", sb.String())
+		require.Equal(t, []string{"Version", "Package"}, kept.stanza.Order)
+	})
+}
+
+// stored hands over a stanza it keeps.
+type stored struct {
+	stanza deb822.Stanza
+}
+
+func (s stored) MarshalDeb822() (deb822.Stanza, error) {
+	return s.stanza, nil
+}
+
+func TestUnmarshaler(t *testing.T) {
+	input := "Package: foo\nDescription: the foo tool\nDescription-md5: " + descriptionMD5("the foo tool") + "\n"
+
+	t.Run("value", func(t *testing.T) {
+		var decoded described
+		require.NoError(t, deb822.Unmarshal([]byte(input), &decoded))
+		require.Equal(t, "the foo tool", decoded.Description)
+	})
+
+	t.Run("slice of pointers", func(t *testing.T) {
+		var decoded []*described
+		require.NoError(t, deb822.Unmarshal([]byte(input+"\n"+input), &decoded))
+		require.Len(t, decoded, 2)
+		require.Equal(t, "foo", decoded[1].Package)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		tampered := strings.Replace(input, "the foo tool", "the bar tool", 1)
+
+		var decoded []described
+		for item, err := range deb822.Items[described](strings.NewReader(input+"\n"+tampered), openpgp.EntityList{}) {
+			if err != nil {
+				require.ErrorIs(t, err, errDescriptionMismatch)
+
+				var perr *deb822.ParseError
+				require.ErrorAs(t, err, &perr)
+				require.Equal(t, 2, perr.Stanza)
+
+				break
+			}
+
+			decoded = append(decoded, item)
+		}
+		require.Len(t, decoded, 1)
+	})
+}
@@ -9,15 +9,34 @@
 
 package deb822
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"oaklab.hu/debian/deb822/internal/fold"
+)
 
 // Sentinel errors returned (wrapped, with the offending line or field name
-// attached) by the parser. Use errors.Is to test for them.
+// attached) by the parser and the encoder. Use errors.Is to test for them.
 var (
 	// ErrInvalidFieldName is returned in strict mode when a field name does
-	// not conform to Debian Policy 5.1.
+	// not conform to Debian Policy 5.1. Fields are always checked on encode.
 	ErrInvalidFieldName = errors.New("invalid field name")
 
+	// ErrInvalidFieldValue is returned on encode for a value whose lines
+	// would not read back as they are: one whose first line starts or ends
+	// with whitespace, with a later line that ends in whitespace or consists
+	// solely of ".", which a reader takes for a folded empty line, or one of
+	// empty lines only.
+	ErrInvalidFieldValue = errors.New("invalid field value")
+
 	// ErrDuplicateField is returned in strict mode when a paragraph repeats a
 	// field. Field names are compared case-insensitively.
 	ErrDuplicateField = errors.New("duplicate field")
@@ -30,6 +49,15 @@ var (
 	// before any field has been seen in the current paragraph. This check is
 	// always on, in both lenient and strict mode.
 	ErrUnexpectedContinuation = errors.New("unexpected continuation line")
+
+	// ErrUnknownField is returned by a Decoder under WithDisallowUnknownFields
+	// for every field the value decoded into has no home for.
+	ErrUnknownField = errors.New("unknown field")
+
+	// ErrMissingField is returned when a struct field tagged required has no
+	// value: by a Decoder for a stanza that lacks the field, and by an
+	// Encoder for a struct that leaves it empty.
+	ErrMissingField = errors.New("missing required field")
 )
 
 // readerOptions holds the resolved parser configuration.
@@ -41,6 +69,30 @@ type readerOptions struct {
 	// comments overrides the comment policy. When nil, comments are allowed
 	// unless strict mode is enabled.
 	comments *bool
+
+	// streaming verifies clearsigned input as it is read, rather than
+	// buffering the whole document to verify it up front.
+	streaming bool
+
+	// policy, when set, has every signature over the input checked and
+	// decides on the outcome.
+	policy SignaturePolicy
+
+	// disallowUnknownFields rejects stanza fields that the struct decoded
+	// into has no field for.
+	disallowUnknownFields bool
+
+	// signedBy, when non-empty, restricts the keyring to the keys with these
+	// (normalised) fingerprints.
+	signedBy []string
+
+	// codecs parses field values of third-party types.
+	codecs *Codecs
+
+	// workers, when above one, is the number of goroutines a Decoder decodes
+	// stanzas on, and unordered has it hand them out as they are done.
+	workers   int
+	unordered bool
 }
 
 // allowComments reports whether comment lines are accepted.
@@ -73,6 +125,104 @@ func WithComments(allow bool) ReaderOption {
 	}
 }
 
+// WithDisallowUnknownFields makes a Decoder reject stanzas with fields the
+// struct they are decoded into has no field for, such as a misspelt
+// `Build-Depend`. Every such field of the stanza is reported, each as a
+// ParseError wrapping ErrUnknownField; use errors.Is to test for it. The
+// values of the known fields are decoded all the same.
+//
+// Without this option unknown fields are ignored.
+func WithDisallowUnknownFields() ReaderOption {
+	return func(o *readerOptions) {
+		o.disallowUnknownFields = true
+	}
+}
+
+// WithDecoderCodecs has a Decoder parse field values with the codecs
+// registered in codecs (see Codecs).
+func WithDecoderCodecs(codecs *Codecs) ReaderOption {
+	return func(o *readerOptions) {
+		o.codecs = codecs
+	}
+}
+
+// WithWorkers has a Decoder decode the stanzas of a slice, or of Items and
+// ItemsOf, on n goroutines, for large indices where the parsing of field
+// values dominates. The input is still split into stanzas by a single
+// goroutine. Values are handed out in input order, and the first error in
+// input order ends the decoding, as without the option; see WithUnordered.
+//
+// Unmarshalers and the codecs of WithDecoderCodecs are then called from
+// several goroutines at once. An n of 0 or less uses runtime.GOMAXPROCS(0)
+// goroutines, and an n of 1 decodes on the calling goroutine, as is the
+// default.
+func WithWorkers(n int) ReaderOption {
+	return func(o *readerOptions) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+
+		o.workers = n
+	}
+}
+
+// WithUnordered has a Decoder working under WithWorkers hand out values as
+// they are decoded rather than in input order, which keeps a slow stanza from
+// holding up the others. The first error to come up ends the decoding. It has
+// no effect on its own.
+func WithUnordered() ReaderOption {
+	return func(o *readerOptions) {
+		o.unordered = true
+	}
+}
+
+// WithStreamingVerification verifies clearsigned input as it is read instead of
+// loading the whole document into memory first.
+//
+// Stanzas are handed out as the signed text streams in, before the signature
+// that trails them has been checked. The outcome of the check is reported by
+// the read that reaches the end of the document: the final call to
+// StanzaReader.Next returns the verification error in place of io.EOF, and
+// Signer is only set once it has returned io.EOF. Callers must therefore not
+// act on any stanza until the whole document has been read successfully.
+//
+// Unsigned input is unaffected.
+func WithStreamingVerification() ReaderOption {
+	return func(o *readerOptions) {
+		o.streaming = true
+	}
+}
+
+// WithSignaturePolicy checks every signature over signed input, rather than
+// only the first one made by a key in the keyring, and hands the outcome to
+// policy to decide whether the input is trusted. All signatures that verified
+// are reported by StanzaReader.SignatureReports.
+//
+// Without this option a document is trusted if the first signature made by a
+// key in the keyring verifies.
+func WithSignaturePolicy(policy SignaturePolicy) ReaderOption {
+	return func(o *readerOptions) {
+		o.policy = policy
+	}
+}
+
+// WithSignedBy restricts the keyring signed input is checked against to the
+// keys with the given fingerprints, the way apt pins a repository to the keys
+// listed in the Signed-By field of its last trusted Release file:
+//
+//	deb822.WithSignedBy(previous.SignedBy...)
+//
+// A fingerprint of a primary key allows its signing subkeys too, unless it is
+// followed by an exclamation mark, which pins that exact key. Fingerprints are
+// hexadecimal; case and spaces are ignored. A signature made by any other key
+// in the keyring fails with ErrSignerNotPinned. An empty list lifts the
+// restriction.
+func WithSignedBy(fingerprints ...string) ReaderOption {
+	return func(o *readerOptions) {
+		o.signedBy = normaliseFingerprints(fingerprints)
+	}
+}
+
 // newReaderOptions resolves a list of options into a readerOptions value.
 func newReaderOptions(opts []ReaderOption) readerOptions {
 	var resolved readerOptions
@@ -86,11 +236,171 @@ func newReaderOptions(opts []ReaderOption) readerOptions {
 	return resolved
 }
 
+// encoderOptions holds the resolved encoder configuration.
+type encoderOptions struct {
+	// config is handed to the OpenPGP signer; nil selects go-crypto's
+	// defaults.
+	config *packet.Config
+
+	// signers sign the output alongside the key given to NewEncoder.
+	signers []*openpgp.Entity
+
+	// signingTime, when set, overrides the signing time of config and turns
+	// randomised signatures off.
+	signingTime time.Time
+
+	// document and signature, when set, receive the unsigned output and a
+	// detached signature over it.
+	document  io.Writer
+	signature io.Writer
+
+	// codecs renders field values of third-party types.
+	codecs *Codecs
+
+	// order, when set, is the canonical order fields are sorted into.
+	order FieldOrder
+
+	// style lays out the fields that are written.
+	style fold.Style
+}
+
+// An EncoderOption configures how an Encoder renders and signs its output.
+type EncoderOption func(*encoderOptions)
+
+// WithSigningConfig signs the output with the given OpenPGP configuration,
+// which selects, amongst others, the digest algorithm (DefaultHash) and the
+// signing time (Time). Each signing key uses the digest algorithm if it
+// accepts it, and the first one it accepts otherwise, for the clearsigned
+// output and a detached signature alike.
+func WithSigningConfig(config *packet.Config) EncoderOption {
+	return func(o *encoderOptions) {
+		o.config = config
+	}
+}
+
+// WithSigners has the output signed by the given keys as well as the one
+// passed to NewEncoder, such as both the old and the new archive key during a
+// key rotation. Each signature is made with the primary key of its entity.
+func WithSigners(signers ...*openpgp.Entity) EncoderOption {
+	return func(o *encoderOptions) {
+		o.signers = append(o.signers, signers...)
+	}
+}
+
+// WithSigningTime dates the signatures at the given time and leaves out the
+// random salt notation go-crypto adds by default, so that signing the same
+// document with the same deterministic (RSA or EdDSA) keys produces
+// byte-identical output, as reproducible archive builds require.
+func WithSigningTime(at time.Time) EncoderOption {
+	return func(o *encoderOptions) {
+		o.signingTime = at
+	}
+}
+
+// WithDetachedSignature also writes the output, unsigned, to document and an
+// armored detached signature over it to signature, in the same pass as the
+// clearsigned output, so that a single Encoder produces an archive's
+// `Release`, `Release.gpg` and `InRelease` alike. Pass io.Discard as the
+// Encoder's writer to skip the clearsigned copy.
+//
+// The signature is made by the same keys, and with the same configuration, as
+// the clearsigned output, and is written when the Encoder is closed. document
+// may be nil if the caller keeps the unsigned output some other way.
+func WithDetachedSignature(document, signature io.Writer) EncoderOption {
+	return func(o *encoderOptions) {
+		o.document = document
+		o.signature = signature
+	}
+}
+
+// WithEncoderCodecs has an Encoder render field values with the codecs
+// registered in codecs (see Codecs).
+func WithEncoderCodecs(codecs *Codecs) EncoderOption {
+	return func(o *encoderOptions) {
+		o.codecs = codecs
+	}
+}
+
+// WithFieldOrder sorts the fields of every stanza into the canonical order of
+// a type of document, such as PackagesOrder, instead of writing them in the
+// order of the struct or Stanza they come from. Output then lines up with
+// that of dpkg, field for field.
+func WithFieldOrder(order FieldOrder) EncoderOption {
+	return func(o *encoderOptions) {
+		o.order = order
+	}
+}
+
+// WithEmptyFirstLine writes a field whose value starts with an empty line,
+// such as the Files of a .dsc or a list.NewLineDelimited, with nothing after
+// its colon, the way dpkg writes it, rather than with a trailing space. Lists
+// wrapped under WithWrapWidth then start on a line of their own too, the way
+// wrap-and-sort --short-indent lays them out.
+func WithEmptyFirstLine() EncoderOption {
+	return func(o *encoderOptions) {
+		o.style.EmptyFirstLine = true
+	}
+}
+
+// WithTabContinuations indents continuation lines with a tab rather than a
+// single space.
+func WithTabContinuations() EncoderOption {
+	return func(o *encoderOptions) {
+		o.style.Indent = "\t"
+	}
+}
+
+// WithWrapWidth wraps a list field, such as Depends, Build-Depends or
+// Uploaders, whose field line would be longer than width characters: the
+// entries go one per line, aligned under the first, the way wrap-and-sort lays
+// out a debian/control file. Only the relationship fields, Built-Using,
+// Uploaders and Binary are wrapped; other values, such as a Description or a
+// Maintainer, are written as they are.
+func WithWrapWidth(width int) EncoderOption {
+	return func(o *encoderOptions) {
+		o.style.Width = width
+	}
+}
+
+// newEncoderOptions resolves a list of options into an encoderOptions value.
+func newEncoderOptions(opts []EncoderOption) encoderOptions {
+	var resolved encoderOptions
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&resolved)
+	}
+
+	return resolved
+}
+
+// signingConfig returns the configuration to sign with, applying the signing
+// time to a copy of the one given.
+func (o encoderOptions) signingConfig() *packet.Config {
+	if o.signingTime.IsZero() {
+		return o.config
+	}
+
+	var config packet.Config
+	if o.config != nil {
+		config = *o.config
+	}
+
+	at := o.signingTime
+	randomised := false
+
+	config.Time = func() time.Time { return at }
+	config.NonDeterministicSignaturesViaNotation = &randomised
+
+	return &config
+}
+
 // validFieldName reports whether name is a valid field name per Debian Policy
 // 5.1: it must be non-empty, must not begin with '#' or '-', and must consist
 // solely of US-ASCII characters in the range 0x21 to 0x7E excluding ':'.
-func validFieldName(name string) bool {
-	if name == "" {
+func validFieldName[T string | []byte](name T) bool {
+	if len(name) == 0 {
 		return false
 	}
 
@@ -106,3 +416,37 @@ func validFieldName(name string) bool {
 
 	return true
 }
+
+// validFieldValue checks that the lines of value read back as they are once
+// folded, failing with ErrInvalidFieldValue. Its first line is written after
+// the field name, which a reader trims, so it must not start or end with
+// whitespace; it may be empty, as it is for a list that starts on a line of
+// its own. A later line must not end in whitespace, which a reader trims too,
+// nor be a lone ".", the folded form of an empty line; and a value of empty
+// lines only reads back as another.
+//
+// A final newline is not checked: folding drops it, and a reader ends the
+// value of every field that spans several lines with one, so a value decoded
+// from such a field, even one holding a single line, encodes as it was read.
+func validFieldValue(value string) error {
+	first, rest, multiline := strings.Cut(value, "\n")
+	if strings.TrimSpace(first) != first {
+		return fmt.Errorf("%w: whitespace around the first line", ErrInvalidFieldValue)
+	}
+
+	if multiline && strings.Trim(value, "\n") == "" {
+		return fmt.Errorf("%w: empty lines only", ErrInvalidFieldValue)
+	}
+
+	for line := range strings.SplitSeq(rest, "\n") {
+		if strings.TrimRightFunc(line, unicode.IsSpace) != line {
+			return fmt.Errorf("%w: line ending in whitespace", ErrInvalidFieldValue)
+		}
+
+		if line == "." {
+			return fmt.Errorf("%w: line consisting solely of '.'", ErrInvalidFieldValue)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"io"
+	"maps"
+	"reflect"
+	"sync"
+)
+
+// decodeJob is a stanza handed to a decoding worker, along with where it was
+// read, to locate the errors decoding it returns.
+type decodeJob struct {
+	index      int
+	stanza     *Stanza
+	number     int
+	fieldLines map[string]int
+}
+
+// decodeResult is the outcome of a decodeJob, or the error that ended the
+// reading of stanzas.
+type decodeResult struct {
+	index int
+	value reflect.Value
+	err   error
+}
+
+// decodeParallel decodes the remaining stanzas into new values of type flavor
+// on the workers set by WithWorkers, and hands the values to yield: in input
+// order, or as they are done under WithUnordered. It stops at the first error
+// (the first in input order, unless unordered), which it returns, or once
+// yield returns false.
+//
+// One goroutine splits the input into stanzas, ahead of the values yielded by
+// no more than a few stanzas per worker. Those it has read ahead are lost when
+// decoding stops early.
+func (d *Decoder) decodeParallel(flavor reflect.Type, yield func(reflect.Value) bool) error {
+	opts := d.stanzaReader.opts
+
+	jobs := make(chan decodeJob, opts.workers)
+	results := make(chan decodeResult, opts.workers)
+	tokens := make(chan struct{}, 4*opts.workers)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(1 + opts.workers)
+
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+
+		d.readJobs(jobs, results, tokens, done)
+	}()
+
+	for range opts.workers {
+		go func() {
+			defer wg.Done()
+
+			for job := range jobs {
+				target := reflect.New(flavor)
+
+				err := decodeStruct(*job.stanza, target, opts)
+				if err != nil {
+					err = locate(err, job.number, job.fieldLines)
+				}
+
+				select {
+				case results <- decodeResult{index: job.index, value: target.Elem(), err: err}:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// stop has the goroutines wind down, and waits for them to do so, so that
+	// the Decoder is left to a single user again.
+	stop := func() {
+		close(done)
+		for range results {
+		}
+	}
+
+	// emit hands a result over, and reports whether to carry on.
+	emit := func(result decodeResult) (bool, error) {
+		if result.err != nil {
+			return false, result.err
+		}
+
+		if !yield(result.value) {
+			return false, nil
+		}
+
+		<-tokens
+
+		return true, nil
+	}
+
+	pending := make(map[int]decodeResult)
+	next := 0
+
+	for result := range results {
+		if opts.unordered {
+			if more, err := emit(result); !more {
+				stop()
+				return err
+			}
+
+			continue
+		}
+
+		pending[result.index] = result
+
+		for {
+			result, found := pending[next]
+			if !found {
+				break
+			}
+
+			delete(pending, next)
+			next++
+
+			if more, err := emit(result); !more {
+				stop()
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// readJobs splits the input into stanzas for the workers of decodeParallel.
+// Every stanza takes a token, given back once its value has been yielded,
+// which bounds how far reading runs ahead. An error ends the reading; it is
+// handed over as the result of the stanza that failed to read.
+func (d *Decoder) readJobs(jobs chan<- decodeJob, results chan<- decodeResult, tokens chan<- struct{}, done <-chan struct{}) {
+	pr := &d.stanzaReader
+
+	for index := 0; ; index++ {
+		select {
+		case <-done:
+			return
+		case tokens <- struct{}{}:
+		}
+
+		stanza, err := pr.Next()
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			select {
+			case results <- decodeResult{index: index, err: err}:
+			case <-done:
+			}
+
+			return
+		}
+
+		job := decodeJob{
+			index:      index,
+			stanza:     stanza,
+			number:     pr.Scanner().stanzas,
+			fieldLines: maps.Clone(pr.fieldLines),
+		}
+
+		select {
+		case jobs <- job:
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+	"oaklab.hu/debian/deb822/types/dependency"
+	"oaklab.hu/debian/deb822/types/version"
+)
+
+type indexEntry struct {
+	Package string                `debian:"Package"`
+	Version version.Version       `debian:"Version"`
+	Depends dependency.Dependency `debian:"Depends,omitempty"`
+	Size    int                   `debian:"Size"`
+}
+
+// index renders n stanzas, the one numbered broken (if any) with a Size that
+// does not parse. Every stanza takes five lines, the blank one included.
+func index(n, broken int) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		size := fmt.Sprint(i)
+		if i == broken {
+			size = "many"
+		}
+
+		fmt.Fprintf(&sb, "Package: pkg%d\nVersion: 1.%d-1\nDepends: libc6 (>= 2.%d)\nSize: %s\n\n", i, i, i, size)
+	}
+
+	return sb.String()
+}
+
+func TestParallelDecode(t *testing.T) {
+	input := index(500, 0)
+
+	var want []indexEntry
+	require.NoError(t, deb822.Unmarshal([]byte(input), &want))
+	require.Len(t, want, 500)
+
+	t.Run("slice", func(t *testing.T) {
+		var got []indexEntry
+		require.NoError(t, deb822.Unmarshal([]byte(input), &got, deb822.WithWorkers(4)))
+		require.Equal(t, want, got)
+	})
+
+	t.Run("items", func(t *testing.T) {
+		var got []indexEntry
+		for item, err := range deb822.Items[indexEntry](strings.NewReader(input), nil, deb822.WithWorkers(0)) {
+			require.NoError(t, err)
+			got = append(got, item)
+		}
+		require.Equal(t, want, got)
+	})
+
+	t.Run("pointers", func(t *testing.T) {
+		var got []*indexEntry
+		require.NoError(t, deb822.Unmarshal([]byte(input), &got, deb822.WithWorkers(4)))
+		require.Len(t, got, 500)
+		require.Equal(t, want[499], *got[499])
+	})
+
+	t.Run("unordered", func(t *testing.T) {
+		var got []indexEntry
+		for item, err := range deb822.Items[indexEntry](strings.NewReader(input), nil, deb822.WithWorkers(4), deb822.WithUnordered()) {
+			require.NoError(t, err)
+			got = append(got, item)
+		}
+
+		slices.SortFunc(got, func(a, b indexEntry) int { return a.Size - b.Size })
+		require.Equal(t, want, got)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		var got []indexEntry
+		var errs []error
+		for item, err := range deb822.Items[indexEntry](strings.NewReader(index(500, 250)), nil, deb822.WithWorkers(4)) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			got = append(got, item)
+		}
+
+		require.Equal(t, want[:249], got)
+		require.Len(t, errs, 1)
+
+		var perr *deb822.ParseError
+		require.ErrorAs(t, errs[0], &perr)
+		require.Equal(t, 250, perr.Stanza)
+		require.Equal(t, 249*5+4, perr.Line)
+		require.Equal(t, "Size", perr.Field)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		var got []indexEntry
+		err := deb822.Unmarshal([]byte(index(10, 0)+"Package: a\nPackage: b\n"), &got, deb822.WithWorkers(4), deb822.WithStrict())
+		require.ErrorIs(t, err, deb822.ErrDuplicateField)
+		require.Len(t, got, 10)
+	})
+
+	t.Run("break", func(t *testing.T) {
+		decoder, err := deb822.NewDecoder(strings.NewReader(input), nil, deb822.WithWorkers(4))
+		require.NoError(t, err)
+
+		var got []indexEntry
+		for item, err := range deb822.ItemsOf[indexEntry](decoder) {
+			require.NoError(t, err)
+			got = append(got, item)
+			if len(got) == 10 {
+				break
+			}
+		}
+		require.Equal(t, want[:10], got)
+
+		// The stanzas read ahead are gone, but the decoder is usable again.
+		var rest []indexEntry
+		require.NoError(t, decoder.Decode(&rest))
+		require.NotEmpty(t, rest)
+		require.Equal(t, want[len(want)-1], rest[len(rest)-1])
+	})
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// ErrSignaturePolicy is returned (wrapped, with the reason attached) when the
+// signatures over a document do not satisfy the SignaturePolicy in effect.
+var ErrSignaturePolicy = errors.New("signature policy not satisfied")
+
+// SignatureFailure is a signature over a document that did not verify, either
+// because no key in the keyring made it or because it is not valid.
+type SignatureFailure struct {
+	// Signature is the signature packet that failed.
+	Signature *packet.Signature
+	// Err is the reason it failed.
+	Err error
+}
+
+func (f SignatureFailure) Error() string {
+	if f.Signature.IssuerKeyId == nil {
+		return f.Err.Error()
+	}
+
+	return fmt.Sprintf("signature by key %016X: %s", *f.Signature.IssuerKeyId, f.Err)
+}
+
+func (f SignatureFailure) Unwrap() error {
+	return f.Err
+}
+
+// A SignaturePolicy decides whether a document is to be trusted, given every
+// signature over it: those that verified, in the order they appear, and those
+// that did not. It returns nil to accept the document.
+//
+// Signatures are always checked against the keyring the reader was created
+// with; a policy only weighs the outcome.
+type SignaturePolicy func(valid []*SignatureReport, failed []SignatureFailure) error
+
+// RequireAnySignature accepts a document as long as at least one of its
+// signatures verifies, ignoring the others.
+func RequireAnySignature() SignaturePolicy {
+	return func(valid []*SignatureReport, failed []SignatureFailure) error {
+		if len(valid) == 0 {
+			return fmt.Errorf("%w: no valid signature: %w", ErrSignaturePolicy, joinFailures(failed))
+		}
+
+		return nil
+	}
+}
+
+// RequireAllSignatures accepts a document only if every one of its signatures
+// verifies, so a signature by a key missing from the keyring fails it too.
+func RequireAllSignatures() SignaturePolicy {
+	return func(valid []*SignatureReport, failed []SignatureFailure) error {
+		if len(failed) > 0 {
+			return fmt.Errorf("%w: %w", ErrSignaturePolicy, joinFailures(failed))
+		}
+
+		if len(valid) == 0 {
+			return fmt.Errorf("%w: no valid signature", ErrSignaturePolicy)
+		}
+
+		return nil
+	}
+}
+
+// RequireKeyrings accepts a document if at least n of the given keyrings have
+// each made a valid signature over it, such as both the old and the new
+// archive key during a key rotation:
+//
+//	deb822.RequireKeyrings(2, oldKey, newKey)
+//
+// Each signing key counts for a single keyring, so a key that two of the
+// keyrings hold does not satisfy both on its own. The keys of every keyring
+// must also be in the keyring the reader checks signatures against. An n
+// below 1 rejects every document, rather than accepting one no key signed.
+func RequireKeyrings(n int, keyrings ...openpgp.EntityList) SignaturePolicy {
+	return func(valid []*SignatureReport, failed []SignatureFailure) error {
+		if n < 1 {
+			return fmt.Errorf("%w: %d keyrings required, at least 1 must be", ErrSignaturePolicy, n)
+		}
+
+		if signed := signedKeyrings(keyrings, valid); signed < n {
+			return fmt.Errorf("%w: signed by %d of %d keyrings, %d required", ErrSignaturePolicy, signed, len(keyrings), n)
+		}
+
+		return nil
+	}
+}
+
+// signedKeyrings returns the number of keyrings that have signed, with a
+// distinct key each: the size of a maximum matching of keyrings to the keys
+// of the valid signatures they hold.
+func signedKeyrings(keyrings []openpgp.EntityList, valid []*SignatureReport) int {
+	// The keys that signed, each once however many signatures it made.
+	var keys []*SignatureReport
+	for _, report := range valid {
+		if !slices.ContainsFunc(keys, func(key *SignatureReport) bool {
+			return bytes.Equal(key.Fingerprint, report.Fingerprint)
+		}) {
+			keys = append(keys, report)
+		}
+	}
+
+	// owner[i] is the keyring the i-th key counts for, or -1.
+	owner := make([]int, len(keys))
+	for i := range owner {
+		owner[i] = -1
+	}
+
+	// assign finds a key for a keyring, taking one from the keyring it counts
+	// for if that keyring can do with another.
+	var assign func(keyring int, seen []bool) bool
+	assign = func(keyring int, seen []bool) bool {
+		for i, key := range keys {
+			if seen[i] || !keyringHolds(keyrings[keyring], key) {
+				continue
+			}
+			seen[i] = true
+
+			if owner[i] < 0 || assign(owner[i], seen) {
+				owner[i] = keyring
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var signed int
+	for keyring := range keyrings {
+		if assign(keyring, make([]bool, len(keys))) {
+			signed++
+		}
+	}
+
+	return signed
+}
+
+// keyringHolds reports whether keyring holds the key a signature was made
+// with.
+func keyringHolds(keyring openpgp.EntityList, report *SignatureReport) bool {
+	for _, key := range keyring.KeysById(report.KeyID) {
+		if bytes.Equal(key.PublicKey.Fingerprint, report.Fingerprint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// joinFailures joins the reasons a set of signatures failed.
+func joinFailures(failed []SignatureFailure) error {
+	errs := make([]error, len(failed))
+	for i := range failed {
+		errs[i] = failed[i]
+	}
+
+	return errors.Join(errs...)
+}
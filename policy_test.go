@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+)
+
+func TestSignaturePolicyInRelease(t *testing.T) {
+	keyringFile, err := os.Open("testdata/archive-key-12.asc")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, keyringFile.Close())
+	})
+
+	keyring, err := openpgp.ReadArmoredKeyRing(keyringFile)
+	require.NoError(t, err)
+
+	// testdata/InRelease carries three signatures, only one of which was made
+	// by the archive key.
+	inRelease, err := os.ReadFile("testdata/InRelease")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		policy deb822.SignaturePolicy
+		want   error
+	}{
+		{name: "any", policy: deb822.RequireAnySignature()},
+		{name: "all", policy: deb822.RequireAllSignatures(), want: pgperrors.ErrUnknownIssuer},
+		{name: "one keyring", policy: deb822.RequireKeyrings(1, keyring)},
+		{
+			name:   "two keyrings",
+			policy: deb822.RequireKeyrings(2, keyring, openpgp.EntityList{newTestEntity(t)}),
+			want:   deb822.ErrSignaturePolicy,
+		},
+	}
+
+	for _, tt := range tests {
+		for mode, opts := range map[string][]deb822.ReaderOption{
+			"buffered":  {deb822.WithSignaturePolicy(tt.policy)},
+			"streaming": {deb822.WithSignaturePolicy(tt.policy), deb822.WithStreamingVerification()},
+		} {
+			t.Run(tt.name+" "+mode, func(t *testing.T) {
+				reader, err := deb822.NewStanzaReader(bytes.NewReader(inRelease), keyring, opts...)
+				if err == nil {
+					_, err = reader.All()
+				}
+
+				if tt.want != nil {
+					require.ErrorIs(t, err, deb822.ErrSignaturePolicy)
+					require.ErrorIs(t, err, tt.want)
+					return
+				}
+
+				require.NoError(t, err)
+				require.Len(t, reader.SignatureReports(), 1)
+				require.Equal(t, keyring[0], reader.Signer())
+			})
+		}
+	}
+}
+
+func TestSignaturePolicyKeyRotation(t *testing.T) {
+	oldKey := newTestEntity(t)
+	newKey := newTestEntity(t)
+	keyring := openpgp.EntityList{oldKey, newKey}
+
+	var signed bytes.Buffer
+	w, err := clearsign.EncodeMulti(&signed, []*packet.PrivateKey{oldKey.PrivateKey, newKey.PrivateKey}, nil)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(detachedRelease))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	t.Run("both keys sign", func(t *testing.T) {
+		policy := deb822.RequireKeyrings(2, openpgp.EntityList{oldKey}, openpgp.EntityList{newKey})
+
+		for _, opts := range [][]deb822.ReaderOption{
+			{deb822.WithSignaturePolicy(policy)},
+			{deb822.WithSignaturePolicy(policy), deb822.WithStreamingVerification()},
+		} {
+			reader, err := deb822.NewStanzaReader(bytes.NewReader(signed.Bytes()), keyring, opts...)
+			require.NoError(t, err)
+
+			blocks, err := reader.All()
+			require.NoError(t, err)
+			require.Len(t, blocks, 2)
+
+			reports := reader.SignatureReports()
+			require.Len(t, reports, 2)
+			require.Equal(t, oldKey, reports[0].Signer)
+			require.Equal(t, newKey, reports[1].Signer)
+		}
+	})
+
+	t.Run("a key counts for one keyring only", func(t *testing.T) {
+		var single bytes.Buffer
+		w, err := clearsign.Encode(&single, oldKey.PrivateKey, nil)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(detachedRelease))
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+
+		_, err = deb822.NewStanzaReader(
+			bytes.NewReader(single.Bytes()), keyring,
+			deb822.WithSignaturePolicy(deb822.RequireKeyrings(2, openpgp.EntityList{oldKey}, openpgp.EntityList{oldKey, newKey})),
+		)
+		require.ErrorIs(t, err, deb822.ErrSignaturePolicy)
+
+		// Both keys sign: the old one counts for the keyring that only holds
+		// it, whichever keyring comes first.
+		_, err = deb822.NewStanzaReader(
+			bytes.NewReader(signed.Bytes()), keyring,
+			deb822.WithSignaturePolicy(deb822.RequireKeyrings(2, openpgp.EntityList{oldKey, newKey}, openpgp.EntityList{oldKey})),
+		)
+		require.NoError(t, err)
+	})
+
+	t.Run("without a policy only one signature is checked", func(t *testing.T) {
+		reader, err := deb822.NewStanzaReader(bytes.NewReader(signed.Bytes()), keyring)
+		require.NoError(t, err)
+		require.Len(t, reader.SignatureReports(), 1)
+	})
+
+	t.Run("new key missing from the keyring", func(t *testing.T) {
+		_, err := deb822.NewStanzaReader(
+			bytes.NewReader(signed.Bytes()), openpgp.EntityList{oldKey},
+			deb822.WithSignaturePolicy(deb822.RequireAllSignatures()),
+		)
+		require.ErrorIs(t, err, deb822.ErrSignaturePolicy)
+	})
+
+	t.Run("no key of the keyring signs", func(t *testing.T) {
+		var signature bytes.Buffer
+		require.NoError(t, openpgp.DetachSign(&signature, newTestEntity(t), strings.NewReader(detachedRelease), nil))
+
+		for _, policy := range []deb822.SignaturePolicy{
+			deb822.RequireKeyrings(0, openpgp.EntityList{oldKey}),
+			deb822.RequireKeyrings(-1),
+			deb822.RequireKeyrings(1),
+		} {
+			_, err := deb822.NewDetachedDecoder(
+				strings.NewReader(detachedRelease), bytes.NewReader(signature.Bytes()), keyring,
+				deb822.WithSignaturePolicy(policy),
+			)
+			require.ErrorIs(t, err, deb822.ErrSignaturePolicy)
+		}
+	})
+
+	t.Run("detached", func(t *testing.T) {
+		var signature bytes.Buffer
+		require.NoError(t, openpgp.DetachSign(&signature, oldKey, strings.NewReader(detachedRelease), nil))
+		require.NoError(t, openpgp.DetachSign(&signature, newKey, strings.NewReader(detachedRelease), nil))
+
+		decoder, err := deb822.NewDetachedDecoder(
+			strings.NewReader(detachedRelease), &signature, keyring,
+			deb822.WithSignaturePolicy(deb822.RequireAllSignatures()),
+		)
+		require.NoError(t, err)
+		require.Len(t, decoder.SignatureReports(), 2)
+	})
+}
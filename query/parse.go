@@ -0,0 +1,322 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"oaklab.hu/debian/deb822/types/version"
+)
+
+// token is a word of an expression. A quoted token is always a pattern, even
+// one that looks like an option.
+type token struct {
+	text   string
+	quoted bool
+}
+
+// Parse parses an expression, split into words the way a shell would: on
+// whitespace, with single quotes, double quotes and backslashes to keep
+// characters together. A quoted word is always taken for a pattern.
+func Parse(expr string) (*Query, error) {
+	tokens, err := split(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(tokens)
+}
+
+// ParseArgs parses an expression already split into words, such as the
+// command line arguments grep-dctrl would get.
+func ParseArgs(args []string) (*Query, error) {
+	tokens := make([]token, len(args))
+	for i, arg := range args {
+		tokens[i] = token{text: arg}
+	}
+
+	return parse(tokens)
+}
+
+// split splits an expression into words.
+func split(expr string) ([]token, error) {
+	var tokens []token
+	var current strings.Builder
+	var inWord, quoted bool
+
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				tokens = append(tokens, token{text: current.String(), quoted: quoted})
+				current.Reset()
+				inWord, quoted = false, false
+			}
+		case c == '\\':
+			if i+1 == len(expr) {
+				return nil, fmt.Errorf("%w: trailing backslash", ErrSyntax)
+			}
+
+			i++
+			current.WriteByte(expr[i])
+			inWord = true
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(expr[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrSyntax)
+			}
+
+			text := expr[i+1 : i+1+end]
+			if c == '"' {
+				text = strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(text)
+			}
+
+			current.WriteString(text)
+			inWord, quoted = true, true
+			i += end + 1
+		default:
+			current.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		tokens = append(tokens, token{text: current.String(), quoted: quoted})
+	}
+
+	return tokens, nil
+}
+
+// parser is a recursive descent parser over the words of an expression.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(tokens []token) (*Query, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%w: empty expression", ErrSyntax)
+	}
+
+	p := parser{tokens: tokens}
+
+	root, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("%w: unexpected '%s'", ErrSyntax, p.tokens[p.pos].text)
+	}
+
+	return &Query{root: root}, nil
+}
+
+// accept consumes the next word if it is an unquoted one of words.
+func (p *parser) accept(words ...string) bool {
+	if p.pos == len(p.tokens) || p.tokens[p.pos].quoted {
+		return false
+	}
+
+	for _, word := range words {
+		if p.tokens[p.pos].text == word {
+			p.pos++
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("-o", "--or") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.not()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.accept("-a", "--and") {
+		right, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andNode{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) not() (node, error) {
+	if p.accept("-v", "--not", "!") {
+		operand, err := p.not()
+		if err != nil {
+			return nil, err
+		}
+
+		return notNode{operand: operand}, nil
+	}
+
+	if p.accept("(") {
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.accept(")") {
+			return nil, fmt.Errorf("%w: missing ')'", ErrSyntax)
+		}
+
+		return inner, nil
+	}
+
+	return p.atom()
+}
+
+// versionRelations maps the version comparison options onto the results of
+// version.Compare they accept.
+var versionRelations = map[string]func(cmp int) bool{
+	"--eq": func(cmp int) bool { return cmp == 0 },
+	"--lt": func(cmp int) bool { return cmp < 0 },
+	"--le": func(cmp int) bool { return cmp <= 0 },
+	"--gt": func(cmp int) bool { return cmp > 0 },
+	"--ge": func(cmp int) bool { return cmp >= 0 },
+}
+
+// atom parses the options of an atomic test up to its pattern.
+func (p *parser) atom() (node, error) {
+	var a atom
+	var exact, regex bool
+
+	for {
+		if p.pos == len(p.tokens) {
+			return nil, fmt.Errorf("%w: missing pattern", ErrSyntax)
+		}
+
+		tok := p.tokens[p.pos]
+		p.pos++
+
+		if tok.quoted || !strings.HasPrefix(tok.text, "-") {
+			a.pattern = tok.text
+			break
+		}
+
+		option, value, hasValue := strings.Cut(tok.text, "=")
+		if !hasValue && strings.HasPrefix(option, "-F") && len(option) > 2 {
+			option, value, hasValue = "-F", option[2:], true
+		}
+
+		switch option {
+		case "-F", "--field":
+			if !hasValue {
+				if p.pos == len(p.tokens) {
+					return nil, fmt.Errorf("%w: missing field name after '%s'", ErrSyntax, option)
+				}
+
+				value = p.tokens[p.pos].text
+				p.pos++
+			}
+
+			for field := range strings.SplitSeq(value, ",") {
+				names := strings.Split(field, ":")
+				if slices.Contains(names, "") {
+					return nil, fmt.Errorf("%w: invalid field name '%s'", ErrSyntax, field)
+				}
+
+				a.fields = append(a.fields, names)
+			}
+
+			continue
+		case "-P":
+			a.fields = append(a.fields, []string{"Package"})
+			continue
+		case "-S":
+			a.fields = append(a.fields, []string{"Source", "Package"})
+			continue
+		}
+
+		if hasValue {
+			return nil, fmt.Errorf("%w: unexpected value for '%s'", ErrSyntax, option)
+		}
+
+		switch option {
+		case "-X", "--exact-match":
+			exact = true
+		case "-e", "--eregex", "-r", "--regex":
+			regex = true
+		case "-i", "--ignore-case":
+			a.ignoreCase = true
+		default:
+			accept, found := versionRelations[option]
+			if !found {
+				return nil, fmt.Errorf("%w: unknown option '%s'", ErrSyntax, option)
+			} else if a.accept != nil {
+				return nil, fmt.Errorf("%w: more than one version comparison", ErrSyntax)
+			}
+
+			a.accept = accept
+		}
+	}
+
+	switch {
+	case a.accept != nil:
+		if exact || regex || a.ignoreCase {
+			return nil, fmt.Errorf("%w: a version comparison takes no other match option", ErrSyntax)
+		}
+
+		v, err := version.Parse(a.pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w: version '%s': %w", ErrSyntax, a.pattern, err)
+		}
+
+		a.mode, a.version = modeVersion, v
+	case regex:
+		expr := a.pattern
+		if exact {
+			expr = "^(?:" + expr + ")$"
+		}
+		if a.ignoreCase {
+			expr = "(?i)" + expr
+		}
+
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: pattern '%s': %w", ErrSyntax, a.pattern, err)
+		}
+
+		a.mode, a.re = modeRegexp, re
+	case exact:
+		a.mode = modeExact
+	}
+
+	return &a, nil
+}
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+// Package query selects stanzas the way grep-dctrl does, with the same
+// expression syntax:
+//
+//	q, err := query.Parse(`-F Section -e ^python -a -F Depends libc6`)
+//	...
+//	for stanza, err := range q.Filter(reader.Stanzas()) {
+//		...
+//	}
+//
+// An expression combines atomic tests with -a (--and), -o (--or), -v (--not,
+// or !) and parentheses; -v binds tightest, then -a, then -o. The operators
+// and parentheses are tokens of their own. An atomic test is a list of options
+// followed by a pattern:
+//
+//	-F field, --field=field  test the named fields only, rather than all of
+//	                         them; repeat it or separate names with commas to
+//	                         test several. "Source:Package" tests the Source
+//	                         field, or Package if there is no Source field.
+//	-P, -S                   short for -F Package and -F Source:Package
+//	-X, --exact-match        the field must equal the pattern, rather than
+//	                         contain it
+//	-e, --eregex             the pattern is a regular expression
+//	-r, --regex              likewise; both use the syntax of package regexp
+//	-i, --ignore-case        match regardless of case
+//	--eq, --lt, --le,        compare the field to the pattern as Debian
+//	--gt, --ge               versions, with version.Compare
+//
+// Field names are matched case-insensitively. A version comparison fails for a
+// field that does not hold a valid version.
+package query
+
+import (
+	"errors"
+	"iter"
+	"regexp"
+	"strings"
+
+	"oaklab.hu/debian/deb822"
+	"oaklab.hu/debian/deb822/types/version"
+)
+
+// ErrSyntax is returned, wrapped with the offending token, for an expression
+// that does not parse.
+var ErrSyntax = errors.New("invalid query")
+
+// A Query is a parsed expression, selecting the stanzas it matches. It is safe
+// for concurrent use.
+type Query struct {
+	root node
+}
+
+// Match reports whether the stanza matches the query.
+func (q *Query) Match(stanza deb822.Stanza) bool {
+	return q.root.match(stanza)
+}
+
+// Filter yields the stanzas of stanzas that match the query, such as those of
+// StanzaReader.Stanzas, along with any error reading them.
+func (q *Query) Filter(stanzas iter.Seq2[deb822.Stanza, error]) iter.Seq2[deb822.Stanza, error] {
+	return func(yield func(deb822.Stanza, error) bool) {
+		for stanza, err := range stanzas {
+			if err != nil {
+				yield(stanza, err)
+				return
+			}
+
+			if q.Match(stanza) && !yield(stanza, nil) {
+				return
+			}
+		}
+	}
+}
+
+// node is a part of an expression.
+type node interface {
+	match(stanza deb822.Stanza) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) match(stanza deb822.Stanza) bool {
+	return n.left.match(stanza) && n.right.match(stanza)
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) match(stanza deb822.Stanza) bool {
+	return n.left.match(stanza) || n.right.match(stanza)
+}
+
+type notNode struct{ operand node }
+
+func (n notNode) match(stanza deb822.Stanza) bool {
+	return !n.operand.match(stanza)
+}
+
+// matchMode is the way an atomic test matches a field against its pattern.
+type matchMode int
+
+const (
+	modeSubstring matchMode = iota
+	modeExact
+	modeRegexp
+	modeVersion
+)
+
+// atom is an atomic test.
+type atom struct {
+	// fields lists the fields to test, each a list of names tried in turn
+	// until one is present; all fields are tested when it is empty.
+	fields [][]string
+
+	mode       matchMode
+	ignoreCase bool
+	pattern    string
+
+	// re is the compiled pattern of modeRegexp.
+	re *regexp.Regexp
+
+	// version and accept are the pattern of modeVersion and the results of
+	// version.Compare that pass.
+	version version.Version
+	accept  func(cmp int) bool
+}
+
+func (a *atom) match(stanza deb822.Stanza) bool {
+	if len(a.fields) == 0 {
+		for _, name := range stanza.Order {
+			if a.matchValue(stanza.Values[name]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, names := range a.fields {
+		for _, name := range names {
+			if value, found := stanza.Get(name); found {
+				if a.matchValue(value) {
+					return true
+				}
+
+				break
+			}
+		}
+	}
+
+	return false
+}
+
+// matchValue reports whether a field value passes the test.
+func (a *atom) matchValue(value string) bool {
+	switch a.mode {
+	case modeExact:
+		if a.ignoreCase {
+			return strings.EqualFold(value, a.pattern)
+		}
+
+		return value == a.pattern
+	case modeRegexp:
+		return a.re.MatchString(value)
+	case modeVersion:
+		v, err := version.Parse(strings.TrimSpace(value))
+		if err != nil {
+			return false
+		}
+
+		return a.accept(v.Compare(a.version))
+	}
+
+	if a.ignoreCase {
+		return strings.Contains(strings.ToLower(value), strings.ToLower(a.pattern))
+	}
+
+	return strings.Contains(value, a.pattern)
+}
@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package query_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+	"oaklab.hu/debian/deb822/query"
+)
+
+const packages = `Package: python3-requests
+Source: requests
+Version: 2.28.1+dfsg-1
+Section: python
+Depends: python3-certifi, python3-chardet, python3:any
+
+Package: hello
+Version: 2.10-3
+Section: devel
+Depends: libc6 (>= 2.34)
+Description: example package based on GNU hello
+ The GNU hello program produces a familiar, friendly greeting.
+
+Package: python3-lxml
+Source: lxml
+Version: 4.9.2-1+b1
+Section: python
+Depends: libc6 (>= 2.34), libxml2 (>= 2.9.14), python3 (<< 3.12)
+
+Package: sl
+Version: 5.02-1
+Section: games
+Depends: libc6 (>= 2.34), libncurses6 (>= 6)
+`
+
+// selected returns the names of the packages the expression selects.
+func selected(t *testing.T, expr string) []string {
+	t.Helper()
+
+	q, err := query.Parse(expr)
+	require.NoError(t, err)
+
+	reader, err := deb822.NewStanzaReader(strings.NewReader(packages), nil)
+	require.NoError(t, err)
+
+	var names []string
+	for stanza, err := range q.Filter(reader.Stanzas()) {
+		require.NoError(t, err)
+		names = append(names, stanza.Values["Package"])
+	}
+
+	return names
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		{
+			name: "substring in any field",
+			expr: "friendly",
+			want: []string{"hello"},
+		},
+		{
+			name: "regular expression and substring",
+			expr: "-F Section -e ^python -a -F Depends libc6",
+			want: []string{"python3-lxml"},
+		},
+		{
+			name: "or",
+			expr: "-P hello -o -F Section games",
+			want: []string{"hello", "sl"},
+		},
+		{
+			name: "not binds tighter than and",
+			expr: "-F Depends libc6 -a ! -F Section games",
+			want: []string{"hello", "python3-lxml"},
+		},
+		{
+			name: "and binds tighter than or",
+			expr: "-P sl -o -F Section python -a -F Depends libc6",
+			want: []string{"python3-lxml", "sl"},
+		},
+		{
+			name: "parentheses",
+			expr: "( -P sl -o -F Section python ) -a -F Depends libc6",
+			want: []string{"python3-lxml", "sl"},
+		},
+		{
+			name: "exact match",
+			expr: "-X -F Section py",
+			want: nil,
+		},
+		{
+			name: "exact match ignoring case",
+			expr: "-X -i -FSection PYTHON",
+			want: []string{"python3-requests", "python3-lxml"},
+		},
+		{
+			name: "exact regular expression",
+			expr: "-X -e --field=Package 'python3-.*'",
+			want: []string{"python3-requests", "python3-lxml"},
+		},
+		{
+			name: "field names are case-insensitive",
+			expr: "-F section games",
+			want: []string{"sl"},
+		},
+		{
+			name: "several fields",
+			expr: "-F Source,Description lxml",
+			want: []string{"python3-lxml"},
+		},
+		{
+			name: "field fallback",
+			expr: "-S -X hello -o -S -X lxml",
+			want: []string{"hello", "python3-lxml"},
+		},
+		{
+			name: "version comparison",
+			expr: "-F Version --ge 4.9",
+			want: []string{"python3-lxml", "sl"},
+		},
+		{
+			name: "version comparison with revisions",
+			expr: "-F Version --lt 4.9.2-1+b2 -a -F Version --gt 4.9.2-1",
+			want: []string{"python3-lxml"},
+		},
+		{
+			name: "version comparison of an invalid version",
+			expr: "-F Section --eq 1.0",
+			want: nil,
+		},
+		{
+			name: "quoted patterns are never options",
+			expr: `-F Package "-a" -o -F Depends 'python3 (<< 3.12)'`,
+			want: []string{"python3-lxml"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, selected(t, tt.expr))
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	q, err := query.ParseArgs([]string{"-F", "Depends", "libxml2 (>= 2.9.14)"})
+	require.NoError(t, err)
+
+	reader, err := deb822.NewStanzaReader(strings.NewReader(packages), nil)
+	require.NoError(t, err)
+
+	stanzas, err := reader.All()
+	require.NoError(t, err)
+	require.False(t, q.Match(stanzas[1]))
+	require.True(t, q.Match(stanzas[2]))
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"-F",
+		"-F Package",
+		"-P hello -a",
+		"( -P hello",
+		"-P hello )",
+		"-P hello -o -o -P sl",
+		"--frobnicate hello",
+		"-e -F Package '('",
+		"-F Version --lt --gt 1.0",
+		"-i -F Version --lt 1.0",
+		"-F Version --eq not:a:version",
+		"-F Package, hello",
+		"'unterminated",
+		`trailing\`,
+	} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := query.Parse(expr)
+			require.ErrorIs(t, err, query.ErrSyntax)
+		})
+	}
+}
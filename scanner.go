@@ -0,0 +1,302 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"unicode"
+)
+
+// A Scanner reads stanzas without allocating per line, for hot paths such as
+// walking a whole Packages index. It is what StanzaReader.Next is built on:
+//
+//	scanner := deb822.NewScanner(r)
+//	for scanner.Scan() {
+//		for i := range scanner.Len() {
+//			name, value := scanner.Field(i)
+//			...
+//		}
+//	}
+//	if err := scanner.Err(); err != nil {
+//		...
+//	}
+//
+// Field values are unfolded the way StanzaReader.Next unfolds them. Names and
+// values are spans of a buffer the Scanner reuses, and only hold until the
+// next call to Scan; Stanza copies them out.
+//
+// NewScanner reads plain input. Use StanzaReader.Scanner to scan clearsigned
+// or detached-signed input once its signature has been checked.
+type Scanner struct {
+	reader *bufio.Reader
+	opts   readerOptions
+
+	// buf holds the names and unfolded values of the fields of the stanza
+	// last scanned, and long holds a line that outgrew the buffer of reader.
+	buf  []byte
+	long []byte
+
+	fields []fieldSpan
+	err    error
+
+	// line is the number of input lines consumed so far, and stanzas the
+	// index of the stanza being (or last) scanned; both locate a ParseError.
+	line    int
+	stanzas int
+
+	// offset is the number of input bytes consumed so far, and start and end
+	// the byte range of the fields of the stanza last scanned, which starts
+	// on line startLine.
+	offset     int64
+	start, end int64
+	startLine  int
+}
+
+// fieldSpan locates a field of the stanza last scanned in the buffer of the
+// Scanner, along with the line it starts on.
+type fieldSpan struct {
+	nameStart, nameEnd   int
+	valueStart, valueEnd int
+	line                 int
+}
+
+// NewScanner creates a Scanner reading plain, unsigned stanzas from reader,
+// honouring the parser options among opts, such as WithStrict.
+func NewScanner(reader io.Reader, opts ...ReaderOption) *Scanner {
+	return newScanner(bufio.NewReader(reader), newReaderOptions(opts), 0)
+}
+
+// newScanner creates a Scanner reading from reader, whose first line is the
+// one after line.
+func newScanner(reader *bufio.Reader, opts readerOptions, line int) *Scanner {
+	return &Scanner{reader: reader, opts: opts, line: line}
+}
+
+// Scan reads the next stanza, and reports whether there was one. It returns
+// false at the end of the input, or after an error, which Err returns. Like
+// StanzaReader.Next, Scan can be called again after an error to carry on
+// with the lines that follow the one in error.
+func (s *Scanner) Scan() bool {
+	s.err = s.scan()
+
+	return s.err == nil
+}
+
+// Err returns the error that ended the last call to Scan, or nil if it
+// reached the end of the input.
+func (s *Scanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+
+	return s.err
+}
+
+// Len returns the number of fields of the stanza last scanned.
+func (s *Scanner) Len() int {
+	return len(s.fields)
+}
+
+// Field returns the name and value of the i-th field of the stanza last
+// scanned, in input order. They are only valid until the next call to Scan.
+func (s *Scanner) Field(i int) (name, value []byte) {
+	f := s.fields[i]
+
+	return s.buf[f.nameStart:f.nameEnd], s.buf[f.valueStart:f.valueEnd]
+}
+
+// Range returns the byte range of the stanza last scanned in the input: the
+// offset of its first field line and the one past its last line, blank lines
+// and comments around it left out. For signed input read through a
+// StanzaReader, the offsets are those in the signed text.
+func (s *Scanner) Range() (start, end int64) {
+	return s.start, s.end
+}
+
+// Stanza fills a Stanza with the fields of the stanza last scanned, reusing
+// its Order and Values. The names and values it gets share a single string
+// allocation.
+func (s *Scanner) Stanza(into *Stanza) {
+	text := string(s.buf)
+
+	if into.Values == nil {
+		into.Values = make(map[string]string, len(s.fields))
+	} else {
+		clear(into.Values)
+	}
+	into.Order = into.Order[:0]
+
+	for _, f := range s.fields {
+		name := text[f.nameStart:f.nameEnd]
+
+		into.Order = append(into.Order, name)
+		into.Values[name] = text[f.valueStart:f.valueEnd]
+	}
+}
+
+// scan reads the next stanza into the buffer, returning io.EOF at the end of
+// the input.
+func (s *Scanner) scan() error {
+	s.buf = s.buf[:0]
+	s.fields = s.fields[:0]
+	s.stanzas++
+
+	// current is the field continuation lines add to; its value always ends
+	// the buffer.
+	current := -1
+
+	allowComments := s.opts.allowComments()
+
+	for {
+		offset := s.offset
+
+		line, err := s.readLine()
+		if len(line) > 0 {
+			s.line++
+			s.offset += int64(len(line))
+		}
+		if err == io.EOF && len(line) > 0 {
+			// The last line lacks its newline; it is complete all the same.
+			err = nil
+		}
+		if err == io.EOF {
+			if len(s.fields) > 0 {
+				return nil
+			}
+
+			return io.EOF
+		} else if err != nil {
+			return err
+		}
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			if len(s.fields) == 0 {
+				// Skip over any number of blank lines between paragraphs.
+				continue
+			}
+
+			// A blank line ends the paragraph.
+			return nil
+		}
+
+		if line[0] == '#' {
+			if !allowComments {
+				return s.errorf("", "%w: '%s'", ErrCommentNotAllowed, bytes.TrimRight(line, "\r\n"))
+			}
+
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			if current < 0 {
+				// A continuation line has nothing to continue.
+				return s.errorf("", "%w: '%s'", ErrUnexpectedContinuation, bytes.TrimRight(line, "\r\n"))
+			}
+
+			// Drop the whitespace the line is folded with, on the left only,
+			// since indentation under it is up to the data format; a lone "."
+			// stands for an empty line.
+			line = bytes.TrimRightFunc(line[1:], unicode.IsSpace)
+			if len(line) == 1 && line[0] == '.' {
+				line = nil
+			}
+
+			f := &s.fields[current]
+			if f.valueEnd > f.valueStart && s.buf[f.valueEnd-1] != '\n' {
+				s.buf = append(s.buf, '\n')
+			}
+			s.buf = append(s.buf, line...)
+			s.buf = append(s.buf, '\n')
+			f.valueEnd = len(s.buf)
+			s.end = s.offset
+
+			continue
+		}
+
+		colon := bytes.IndexByte(line, ':')
+		if colon < 0 {
+			if line[len(line)-1] != '\n' {
+				return s.errorf("", "could not parse line: '%s\n'", line)
+			}
+
+			return s.errorf("", "could not parse line: '%s'", line)
+		}
+
+		if s.opts.strict && !validFieldName(line[:colon]) {
+			// Validate the raw text ahead of the colon, so that names padded
+			// with whitespace ("Key : value") are caught too.
+			return s.errorf(string(line[:colon]), "%w: '%s'", ErrInvalidFieldName, line[:colon])
+		}
+
+		if len(s.fields) == 0 {
+			s.start, s.startLine = offset, s.line
+		}
+		s.end = s.offset
+
+		name := bytes.TrimSpace(line[:colon])
+		value := bytes.TrimSpace(line[colon+1:])
+
+		current = -1
+		for i := range s.fields {
+			known, _ := s.Field(i)
+
+			// Policy 5.1: field names are case-insensitive.
+			if s.opts.strict && bytes.EqualFold(known, name) {
+				return s.errorf(string(name), "%w: '%s'", ErrDuplicateField, name)
+			}
+
+			if bytes.Equal(known, name) {
+				current = i
+				break
+			}
+		}
+
+		if current < 0 {
+			// A new field, with its name ahead of its value in the buffer.
+			current = len(s.fields)
+			s.fields = append(s.fields, fieldSpan{nameStart: len(s.buf)})
+			s.buf = append(s.buf, name...)
+			s.fields[current].nameEnd = len(s.buf)
+		}
+
+		// A field repeated as it was is replaced in place, as Stanza.Set
+		// does; its earlier value stays behind in the buffer unused.
+		f := &s.fields[current]
+		f.valueStart = len(s.buf)
+		s.buf = append(s.buf, value...)
+		f.valueEnd = len(s.buf)
+		f.line = s.line
+	}
+}
+
+// readLine reads a line, its newline included. The line is only valid until
+// the next read.
+func (s *Scanner) readLine() ([]byte, error) {
+	line, err := s.reader.ReadSlice('\n')
+	if err != bufio.ErrBufferFull {
+		return line, err
+	}
+
+	s.long = append(s.long[:0], line...)
+	for err == bufio.ErrBufferFull {
+		line, err = s.reader.ReadSlice('\n')
+		s.long = append(s.long, line...)
+	}
+
+	return s.long, err
+}
+
+// errorf returns a ParseError for the line just read.
+func (s *Scanner) errorf(field string, format string, args ...any) error {
+	return &ParseError{Line: s.line, Stanza: s.stanzas, Field: field, Err: fmt.Errorf(format, args...)}
+}
@@ -0,0 +1,230 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+	"oaklab.hu/debian/deb822/types"
+)
+
+// inReleaseText returns the signed text of testdata/InRelease.
+func inReleaseText(tb testing.TB) []byte {
+	tb.Helper()
+
+	data, err := os.ReadFile("testdata/InRelease")
+	require.NoError(tb, err)
+
+	block, _ := clearsign.Decode(data)
+	require.NotNil(tb, block)
+
+	return block.Bytes
+}
+
+func TestScanner(t *testing.T) {
+	input := "Package: hello\n" +
+		"Description: a greeting\n" +
+		" Longer text.\n" +
+		" .\n" +
+		"  Indented.   \n" +
+		"Package: replaced\n" +
+		"\n\n" +
+		"# a comment\n" +
+		"Files:\n" +
+		" abc 1 a.dsc\r\n" +
+		" def 2 b.tar.xz\n" +
+		"Long: " + strings.Repeat("x", 10000) + "\n" +
+		"Last: no newline"
+
+	scanner := deb822.NewScanner(strings.NewReader(input))
+
+	require.True(t, scanner.Scan())
+	require.Equal(t, 2, scanner.Len())
+
+	name, value := scanner.Field(0)
+	require.Equal(t, "Package", string(name))
+	require.Equal(t, "replaced", string(value))
+
+	name, value = scanner.Field(1)
+	require.Equal(t, "Description", string(name))
+	require.Equal(t, "a greeting\nLonger text.\n\n Indented.\n", string(value))
+
+	var stanza deb822.Stanza
+	scanner.Stanza(&stanza)
+	require.Equal(t, []string{"Package", "Description"}, stanza.Order)
+
+	require.True(t, scanner.Scan())
+	scanner.Stanza(&stanza)
+	require.Equal(t, []string{"Files", "Long", "Last"}, stanza.Order)
+	require.Equal(t, "abc 1 a.dsc\ndef 2 b.tar.xz\n", stanza.Values["Files"])
+	require.Len(t, stanza.Values["Long"], 10000)
+	require.Equal(t, "no newline", stanza.Values["Last"])
+	require.NotContains(t, stanza.Values, "Package")
+
+	require.False(t, scanner.Scan())
+	require.NoError(t, scanner.Err())
+
+	t.Run("matches Next", func(t *testing.T) {
+		reader, err := deb822.NewStanzaReader(strings.NewReader(input), nil)
+		require.NoError(t, err)
+
+		want, err := reader.All()
+		require.NoError(t, err)
+
+		scanner := deb822.NewScanner(strings.NewReader(input))
+		for _, stanza := range want {
+			require.True(t, scanner.Scan())
+
+			var got deb822.Stanza
+			scanner.Stanza(&got)
+			require.Equal(t, stanza, got)
+		}
+		require.False(t, scanner.Scan())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		scanner := deb822.NewScanner(strings.NewReader("A: 1\n\nB: 2\nb: 3\nC: 4\n\nD: 5\n"), deb822.WithStrict())
+
+		require.True(t, scanner.Scan())
+		require.False(t, scanner.Scan())
+		require.ErrorIs(t, scanner.Err(), deb822.ErrDuplicateField)
+
+		var perr *deb822.ParseError
+		require.ErrorAs(t, scanner.Err(), &perr)
+		require.Equal(t, 4, perr.Line)
+		require.Equal(t, 2, perr.Stanza)
+		require.Equal(t, "b", perr.Field)
+
+		// Scanning carries on after the line in error.
+		require.True(t, scanner.Scan())
+		name, _ := scanner.Field(0)
+		require.Equal(t, "C", string(name))
+	})
+
+	t.Run("signed input", func(t *testing.T) {
+		f, err := os.Open("testdata/InRelease")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, f.Close())
+		})
+
+		keyringFile, err := os.Open("testdata/archive-key-12.asc")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, keyringFile.Close())
+		})
+
+		keyring, err := openpgp.ReadArmoredKeyRing(keyringFile)
+		require.NoError(t, err)
+
+		reader, err := deb822.NewStanzaReader(f, keyring)
+		require.NoError(t, err)
+
+		scanner := reader.Scanner()
+		require.True(t, scanner.Scan())
+
+		name, value := scanner.Field(0)
+		require.Equal(t, "Origin", string(name))
+		require.Equal(t, "Debian", string(value))
+	})
+
+	t.Run("allocations", func(t *testing.T) {
+		stanza := "Package: hello\nVersion: 2.10-3\nDescription: a greeting\n more text\n .\n the end\n\n"
+		scanner := deb822.NewScanner(strings.NewReader(strings.Repeat(stanza, 200)))
+
+		allocs := testing.AllocsPerRun(100, func() {
+			if !scanner.Scan() {
+				t.Fatal(scanner.Err())
+			}
+		})
+		require.Zero(t, allocs)
+	})
+}
+
+func BenchmarkStanzaReader(b *testing.B) {
+	text := inReleaseText(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(text)))
+
+	for b.Loop() {
+		reader, err := deb822.NewStanzaReader(bytes.NewReader(text), nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		for {
+			if _, err := reader.Next(); err == io.EOF {
+				break
+			} else if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkScanner(b *testing.B) {
+	text := inReleaseText(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(text)))
+
+	for b.Loop() {
+		scanner := deb822.NewScanner(bytes.NewReader(text))
+		for scanner.Scan() {
+			for i := range scanner.Len() {
+				scanner.Field(i)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkScannerStanza(b *testing.B) {
+	text := inReleaseText(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(text)))
+
+	var stanza deb822.Stanza
+	for b.Loop() {
+		scanner := deb822.NewScanner(bytes.NewReader(text))
+		for scanner.Scan() {
+			scanner.Stanza(&stanza)
+		}
+		if err := scanner.Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeRelease(b *testing.B) {
+	text := inReleaseText(b)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(text)))
+
+	for b.Loop() {
+		var release types.Release
+		if err := deb822.Unmarshal(text, &release); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+)
+
+// ErrSignerNotPinned is returned (wrapped, with the key ID attached) when
+// signed input was signed by a key of the keyring that is not among the
+// fingerprints given to WithSignedBy.
+var ErrSignerNotPinned = errors.New("signing key not pinned by Signed-By")
+
+// normaliseFingerprints uppercases the given fingerprints and strips them of
+// whitespace, dropping those that end up empty.
+func normaliseFingerprints(fingerprints []string) []string {
+	normalised := make([]string, 0, len(fingerprints))
+	for _, fingerprint := range fingerprints {
+		fingerprint = strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+
+			return unicode.ToUpper(r)
+		}, fingerprint)
+
+		if fingerprint != "" {
+			normalised = append(normalised, fingerprint)
+		}
+	}
+
+	return normalised
+}
+
+// pinnedKeys returns the keys allowed by the pinned fingerprints: those whose
+// own fingerprint is pinned, and those whose primary key is pinned without an
+// exclamation mark.
+func pinnedKeys(keys []openpgp.Key, pins []string) []openpgp.Key {
+	var pinned []openpgp.Key
+
+	for _, key := range keys {
+		fingerprint := strings.ToUpper(hex.EncodeToString(key.PublicKey.Fingerprint))
+
+		var primary string
+		if key.Entity != nil && key.Entity.PrimaryKey != nil {
+			primary = strings.ToUpper(hex.EncodeToString(key.Entity.PrimaryKey.Fingerprint))
+		}
+
+		for _, pin := range pins {
+			exact, found := strings.CutSuffix(pin, "!")
+			if exact == fingerprint || (!found && pin == primary) {
+				pinned = append(pinned, key)
+				break
+			}
+		}
+	}
+
+	return pinned
+}
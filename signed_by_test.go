@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+)
+
+func TestSignedByInRelease(t *testing.T) {
+	keyringFile, err := os.Open("testdata/archive-key-12.asc")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, keyringFile.Close())
+	})
+
+	keyring, err := openpgp.ReadArmoredKeyRing(keyringFile)
+	require.NoError(t, err)
+
+	inRelease, err := os.ReadFile("testdata/InRelease")
+	require.NoError(t, err)
+
+	primary := strings.ToUpper(hex.EncodeToString(keyring[0].PrimaryKey.Fingerprint))
+	other := strings.ToUpper(hex.EncodeToString(newTestEntity(t).PrimaryKey.Fingerprint))
+
+	tests := []struct {
+		name     string
+		signedBy []string
+		policy   deb822.SignaturePolicy
+		want     error
+	}{
+		{name: "unrestricted"},
+		{name: "primary key", signedBy: []string{primary}},
+		{name: "primary key among others", signedBy: []string{other, primary}},
+		{name: "lower case with spaces", signedBy: []string{"4cb5 0190 207b 4758 a3f7  3a79 6ed0 e7b8 2643 e131"}},
+		{name: "exact subkey", signedBy: []string{"4CB50190207B4758A3F73A796ED0E7B82643E131!"}},
+		{name: "exact primary key", signedBy: []string{primary + "!"}, want: deb822.ErrSignerNotPinned},
+		{name: "other key", signedBy: []string{other}, want: deb822.ErrSignerNotPinned},
+		{
+			name:     "other key under a policy",
+			signedBy: []string{other},
+			policy:   deb822.RequireAnySignature(),
+			want:     deb822.ErrSignerNotPinned,
+		},
+	}
+
+	for _, tt := range tests {
+		for mode, streaming := range map[string]bool{"buffered": false, "streaming": true} {
+			t.Run(tt.name+" "+mode, func(t *testing.T) {
+				opts := []deb822.ReaderOption{deb822.WithSignedBy(tt.signedBy...)}
+				if streaming {
+					opts = append(opts, deb822.WithStreamingVerification())
+				}
+				if tt.policy != nil {
+					opts = append(opts, deb822.WithSignaturePolicy(tt.policy))
+				}
+
+				reader, err := deb822.NewStanzaReader(bytes.NewReader(inRelease), keyring, opts...)
+				if err == nil {
+					_, err = reader.All()
+				}
+
+				if tt.want != nil {
+					require.ErrorIs(t, err, tt.want)
+					return
+				}
+
+				require.NoError(t, err)
+				require.Equal(t, keyring[0], reader.Signer())
+			})
+		}
+	}
+}
+
+func TestSignedByKeyRotation(t *testing.T) {
+	oldKey := newTestEntity(t)
+	newKey := newTestEntity(t)
+	keyring := openpgp.EntityList{oldKey, newKey}
+
+	var signed bytes.Buffer
+	w, err := clearsign.EncodeMulti(&signed, []*packet.PrivateKey{oldKey.PrivateKey, newKey.PrivateKey}, nil)
+	require.NoError(t, err)
+	_, err = w.Write([]byte(detachedRelease))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	// The Release file signed by the old key hands over to the new one.
+	signedBy := hex.EncodeToString(newKey.PrimaryKey.Fingerprint)
+
+	t.Run("clearsigned", func(t *testing.T) {
+		reader, err := deb822.NewStanzaReader(bytes.NewReader(signed.Bytes()), keyring, deb822.WithSignedBy(signedBy))
+		require.NoError(t, err)
+		require.Equal(t, newKey, reader.Signer())
+	})
+
+	t.Run("policy", func(t *testing.T) {
+		_, err := deb822.NewStanzaReader(
+			bytes.NewReader(signed.Bytes()), keyring,
+			deb822.WithSignedBy(signedBy),
+			deb822.WithSignaturePolicy(deb822.RequireAllSignatures()),
+		)
+		require.ErrorIs(t, err, deb822.ErrSignaturePolicy)
+		require.ErrorIs(t, err, deb822.ErrSignerNotPinned)
+	})
+
+	t.Run("detached", func(t *testing.T) {
+		var signature bytes.Buffer
+		require.NoError(t, openpgp.ArmoredDetachSign(&signature, oldKey, strings.NewReader(detachedRelease), nil))
+
+		_, err := deb822.NewDetachedStanzaReader(
+			strings.NewReader(detachedRelease), &signature, keyring,
+			deb822.WithSignedBy(signedBy),
+		)
+		require.ErrorIs(t, err, deb822.ErrSignerNotPinned)
+	})
+}
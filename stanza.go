@@ -36,6 +36,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
+	"slices"
+	"strings"
 
 	"oaklab.hu/debian/deb822/internal/fold"
 )
@@ -48,7 +51,22 @@ type Stanza struct {
 	Order  []string
 }
 
+// Set sets the value of a field. A field the stanza already has under a name
+// that matches key case-insensitively, as Debian Policy 5.1 requires, takes
+// the value in place and keeps its name; otherwise the field is added last.
 func (p *Stanza) Set(key, value string) {
+	if name, found := p.key(key); found {
+		p.sync()
+		p.Values[name] = value
+		return
+	}
+
+	p.put(key, value)
+}
+
+// put sets the value of a field, its name matched exactly, as a StanzaReader
+// keeps fields whose names only differ in case apart in lenient mode.
+func (p *Stanza) put(key, value string) {
 	if p.Values == nil {
 		p.Values = make(map[string]string)
 	}
@@ -65,11 +83,199 @@ func (p *Stanza) Set(key, value string) {
 	p.Values[key] = value
 }
 
-func (p *Stanza) WriteTo(w io.Writer) (total int64, err error) {
+// Get returns the value of a field, matching its name case-insensitively as
+// Debian Policy 5.1 requires, and whether the stanza has it.
+func (p *Stanza) Get(name string) (string, bool) {
+	key, found := p.key(name)
+	if !found {
+		return "", false
+	}
+
+	return p.Values[key], true
+}
+
+// Has reports whether the stanza has a field, matching its name
+// case-insensitively.
+func (p *Stanza) Has(name string) bool {
+	_, found := p.key(name)
+
+	return found
+}
+
+// Delete removes a field, matched case-insensitively, and reports whether the
+// stanza had it.
+func (p *Stanza) Delete(name string) bool {
+	key, found := p.key(name)
+	if !found {
+		return false
+	}
+
+	p.remove(key)
+
+	return true
+}
+
+// Rename renames a field, matched case-insensitively, keeping its value and
+// its place, and reports whether the stanza had it. Another field already
+// called newName is removed.
+func (p *Stanza) Rename(name, newName string) bool {
+	key, found := p.key(name)
+	if !found {
+		return false
+	}
+
+	p.sync()
+
+	if other, taken := p.key(newName); taken && other != key {
+		p.remove(other)
+	}
+
+	value := p.Values[key]
+	at := slices.Index(p.Order, key)
+	delete(p.Values, key)
+
+	p.Order[at] = newName
+	p.Values[newName] = value
+
+	return true
+}
+
+// InsertBefore sets a field, placing it right before the field called mark,
+// and reports whether the stanza has mark. A field the stanza already has
+// under name is moved there. Both names are matched case-insensitively.
+func (p *Stanza) InsertBefore(mark, name, value string) bool {
+	return p.insert(mark, name, value, 0)
+}
+
+// InsertAfter sets a field, placing it right after the field called mark, and
+// reports whether the stanza has mark. A field the stanza already has under
+// name is moved there. Both names are matched case-insensitively.
+func (p *Stanza) InsertAfter(mark, name, value string) bool {
+	return p.insert(mark, name, value, 1)
+}
+
+// Clone returns a copy of the stanza that shares nothing with it.
+func (p *Stanza) Clone() Stanza {
+	values := maps.Clone(p.Values)
+	if values == nil {
+		values = make(map[string]string)
+	}
+
+	return Stanza{Values: values, Order: slices.Clone(p.Order)}
+}
+
+// key returns the name the stanza has a field under, matching name
+// case-insensitively, and whether it has one. Only names Values holds count.
+func (p *Stanza) key(name string) (string, bool) {
+	if _, found := p.Values[name]; found {
+		return name, true
+	}
+
 	for _, key := range p.Order {
-		value := fold.Value(p.Values[key])
+		if _, found := p.Values[key]; found && strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+
+	// A field Values holds but Order lacks.
+	if !p.inSync() {
+		for _, key := range slices.Sorted(maps.Keys(p.Values)) {
+			if strings.EqualFold(key, name) {
+				return key, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// inSync reports whether Order lists every field of Values, and no other.
+func (p *Stanza) inSync() bool {
+	if len(p.Order) != len(p.Values) {
+		return false
+	}
 
-		n, err := w.Write([]byte(fmt.Sprintf("%s: %s\n", key, value)))
+	for _, key := range p.Order {
+		if _, found := p.Values[key]; !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sync rebuilds Order from Values when the two have drifted apart: it keeps
+// the fields Order lists that Values holds, in their order, and adds the
+// others Values holds last, sorted by name, so that no field is lost.
+func (p *Stanza) sync() {
+	if p.inSync() {
+		return
+	}
+
+	order := make([]string, 0, len(p.Values))
+	for _, key := range p.Order {
+		if _, found := p.Values[key]; found && !slices.Contains(order, key) {
+			order = append(order, key)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(p.Values)) {
+		if !slices.Contains(order, key) {
+			order = append(order, key)
+		}
+	}
+
+	p.Order = order
+}
+
+// insert sets a field, placing it offset places past the field called mark.
+func (p *Stanza) insert(mark, name, value string, offset int) bool {
+	markKey, found := p.key(mark)
+	if !found {
+		return false
+	}
+
+	p.sync()
+
+	if key, found := p.key(name); found {
+		if key == markKey {
+			// The field is its own mark, and stays where it is.
+			p.Values[key] = value
+			return true
+		}
+
+		p.remove(key)
+	}
+
+	at := slices.Index(p.Order, markKey) + offset
+	p.Order = slices.Insert(p.Order, at, name)
+	p.Values[name] = value
+
+	return true
+}
+
+// remove removes a field, its name matched exactly.
+func (p *Stanza) remove(key string) {
+	delete(p.Values, key)
+	p.Order = slices.DeleteFunc(p.Order, func(field string) bool { return field == key })
+}
+
+// WriteTo writes the stanza out, folding multiline values. It fails with
+// ErrInvalidFieldName or ErrInvalidFieldValue, before writing anything, for a
+// field that would not read back as it is.
+func (p *Stanza) WriteTo(w io.Writer) (total int64, err error) {
+	if err := p.validate(); err != nil {
+		return 0, err
+	}
+
+	return p.writeTo(w, fold.Style{})
+}
+
+// writeTo writes the stanza out, laying its fields out in the given style. The
+// caller validates the stanza first.
+func (p *Stanza) writeTo(w io.Writer, style fold.Style) (total int64, err error) {
+	for _, key := range p.Order {
+		n, err := io.WriteString(w, style.Field(key, p.Values[key]))
 		total += int64(n)
 		if err != nil {
 			return total, err
@@ -149,3 +355,28 @@ func (p *Stanza) UnmarshalJSON(data []byte) error {
 
 	return nil
 }
+
+// validate checks that every field of the stanza reads back as it is written.
+func (p *Stanza) validate() error {
+	for _, key := range p.Order {
+		if err := validateField(key, p.Values[key]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateField checks that a field reads back as it is written, failing with
+// ErrInvalidFieldName or ErrInvalidFieldValue.
+func validateField(name, value string) error {
+	if !validFieldName(name) {
+		return fmt.Errorf("%w: '%s'", ErrInvalidFieldName, name)
+	}
+
+	if err := validFieldValue(value); err != nil {
+		return fmt.Errorf("field %q: %w", name, err)
+	}
+
+	return nil
+}
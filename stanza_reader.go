@@ -35,10 +35,8 @@ import (
 	"bufio"
 	"bytes"
 	"errors"
-	"fmt"
 	"io"
-	"strings"
-	"unicode"
+	"iter"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
@@ -51,9 +49,23 @@ import (
 // unread stanza can be returned by calling the `.Next` method on this
 // struct.
 type StanzaReader struct {
-	reader *bufio.Reader
-	signer *openpgp.Entity
-	opts   readerOptions
+	reader  *bufio.Reader
+	reports []*SignatureReport
+	opts    readerOptions
+
+	// stream is the outcome of the signature check of a reader that verifies
+	// as it streams, if WithStreamingVerification is in effect.
+	stream *streamResult
+
+	// line is the number of input lines ahead of the stanzas, the armor
+	// headers of clearsigned input.
+	line int
+
+	// scanner reads the stanzas off reader once it has been set up.
+	scanner *Scanner
+	// fieldLines maps the fields of the last stanza read to the line they
+	// start on.
+	fieldLines map[string]int
 }
 
 // Create a new StanzaReader from the given `io.Reader`, `keyring` and options.
@@ -64,8 +76,9 @@ type StanzaReader struct {
 // clearsigned input fails with an OpenPGP error. Plain, unsigned input never
 // touches the keyring and is unaffected by its contents.
 //
-// Also keep in mind, `reader` may be consumed 100% in memory due to
-// the underlying OpenPGP API being hella fiddly.
+// Also keep in mind, clearsigned `reader`s are consumed 100% in memory due to
+// the underlying OpenPGP API being hella fiddly, unless
+// WithStreamingVerification is given.
 func NewStanzaReader(reader io.Reader, keyring openpgp.EntityList, opts ...ReaderOption) (*StanzaReader, error) {
 	bufioReader := bufio.NewReader(reader)
 	pr := StanzaReader{
@@ -81,6 +94,19 @@ func NewStanzaReader(reader io.Reader, keyring openpgp.EntityList, opts ...Reade
 		return &pr, nil
 	}
 
+	if pr.opts.streaming {
+		clearsign, err := newClearsignReader(bufioReader, keyring, pr.opts)
+		if err != nil {
+			return nil, err
+		}
+
+		pr.stream = &clearsign.streamResult
+		pr.reader = bufio.NewReader(clearsign)
+		pr.line = clearsign.headerLines
+
+		return &pr, nil
+	}
+
 	if err := pr.decodeClearsig(keyring); err != nil {
 		return nil, err
 	}
@@ -89,8 +115,41 @@ func NewStanzaReader(reader io.Reader, keyring openpgp.EntityList, opts ...Reade
 }
 
 // Return the Entity (if one exists) that signed this set of stanzas.
+//
+// With WithStreamingVerification the signature is only checked once the
+// stanzas have been read to the end, and Signer returns nil until then.
 func (pr *StanzaReader) Signer() *openpgp.Entity {
-	return pr.signer
+	if report := pr.SignatureReport(); report != nil {
+		return report.Signer
+	}
+
+	return nil
+}
+
+// Return the report of the signature (if one exists) over this set of
+// stanzas: the signing key, the signature's creation time and digest
+// algorithm, and the key's expiry and revocation state.
+//
+// Like Signer, it is nil until the signature has been checked.
+func (pr *StanzaReader) SignatureReport() *SignatureReport {
+	if reports := pr.SignatureReports(); len(reports) > 0 {
+		return reports[0]
+	}
+
+	return nil
+}
+
+// Return the reports of every signature over this set of stanzas that
+// verified, in the order they appear. Unless WithSignaturePolicy is in effect
+// only a single signature is ever checked.
+//
+// Like Signer, it is nil until the signatures have been checked.
+func (pr *StanzaReader) SignatureReports() []*SignatureReport {
+	if pr.stream != nil {
+		return pr.stream.reports
+	}
+
+	return pr.reports
 }
 
 func (pr *StanzaReader) All() ([]Stanza, error) {
@@ -106,130 +165,87 @@ func (pr *StanzaReader) All() ([]Stanza, error) {
 	}
 }
 
-// Consume the io.Reader and return the next parsed stanza, modulo
-// garbage lines causing us to return an error.
-func (pr *StanzaReader) Next() (*Stanza, error) {
-	var paragraph Stanza
-	var lastKey string
-	var haveField bool
-
-	// Duplicate detection is only ever consulted in strict mode, so don't pay
-	// for the map otherwise.
-	var seen map[string]struct{}
-	if pr.opts.strict {
-		seen = make(map[string]struct{})
-	}
-
-	allowComments := pr.opts.allowComments()
-
-	for {
-		line, err := pr.reader.ReadString('\n')
-		if err == io.EOF && line != "" {
-			err = nil
-			line = line + "\n"
-			// We'll clean up the last of the buffer.
-		}
-		if err == io.EOF {
-			// Let's return the parsed paragraph if we have it.
-			if len(paragraph.Order) > 0 {
-				return &paragraph, nil
+// Stanzas returns an iterator over the remaining stanzas, for use with a range
+// loop. It stops after the last stanza, or after yielding the first error,
+// such as a failed signature check under WithStreamingVerification.
+func (pr *StanzaReader) Stanzas() iter.Seq2[Stanza, error] {
+	return func(yield func(Stanza, error) bool) {
+		for {
+			paragraph, err := pr.Next()
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(Stanza{}, err)
+				return
 			}
-			// Else, let's go ahead and drop the EOF out raw.
-			return nil, err
-		} else if err != nil {
-			return nil, err
-		}
 
-		if strings.TrimSpace(line) == "" {
-			if len(paragraph.Order) == 0 {
-				// Skip over any number of blank lines between paragraphs.
-				continue
+			if !yield(*paragraph, nil) {
+				return
 			}
-			// Lines are ended by a blank line; so we're able to go ahead
-			// and return this guy as-is. All set. Done. Finished.
-			return &paragraph, nil
 		}
+	}
+}
 
-		if strings.HasPrefix(line, "#") {
-			if !allowComments {
-				return nil, fmt.Errorf("%w: '%s'", ErrCommentNotAllowed, strings.TrimRight(line, "\r\n"))
-			}
-
-			continue // skip comments
-		}
+// Consume the io.Reader and return the next parsed stanza, modulo
+// garbage lines causing us to return an error.
+func (pr *StanzaReader) Next() (*Stanza, error) {
+	scanner := pr.Scanner()
 
-		/* Right, so we have a line in one of the following formats:
-		*
-		* "Key: Value"
-		* " Foobar"
-		*
-		* Foobar is seen as a continuation of the last line, and the
-		* Key line is a Key/Value mapping.
-		 */
-
-		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
-			if !haveField {
-				// A continuation line has nothing to continue; without this
-				// guard we'd write into a nil map and panic.
-				return nil, fmt.Errorf("%w: '%s'", ErrUnexpectedContinuation, strings.TrimRight(line, "\r\n"))
-			}
+	if pr.fieldLines == nil {
+		pr.fieldLines = make(map[string]int)
+	}
+	clear(pr.fieldLines)
 
-			/* This is a continuation line; so we're going to go ahead and
-			* clean it up, and throw it into the list. We're going to remove
-			* the first character (which we now know is whitespace), and if
-			* it's a line that only has a dot on it, we'll remove that too
-			* (since " .\n" is actually "\n"). We only trim off space on the
-			* right hand, because indentation under the whitespace is up to
-			* the data format. Not us. */
+	if err := scanner.scan(); err != nil {
+		return nil, err
+	}
 
-			// TrimFunc(line[1:], unicode.IsSpace) is identical to calling TrimSpace.
-			line = strings.TrimRightFunc(line[1:], unicode.IsSpace)
+	var paragraph Stanza
+	scanner.Stanza(&paragraph)
 
-			if line == "." {
-				line = ""
-			}
+	for i, key := range paragraph.Order {
+		pr.fieldLines[key] = scanner.fields[i].line
+	}
 
-			if paragraph.Values[lastKey] == "" {
-				paragraph.Values[lastKey] = line + "\n"
-			} else {
-				if !strings.HasSuffix(paragraph.Values[lastKey], "\n") {
-					paragraph.Values[lastKey] = paragraph.Values[lastKey] + "\n"
-				}
-				paragraph.Values[lastKey] = paragraph.Values[lastKey] + line + "\n"
-			}
-			continue
-		}
+	return &paragraph, nil
+}
 
-		// So, if we're here, we've got a key line. Let's go ahead and split
-		// this on the first key, and set that guy.
-		els := strings.SplitN(line, ":", 2)
-		if len(els) != 2 {
-			return nil, fmt.Errorf("could not parse line: '%s'", line)
-		}
+// Scanner returns the Scanner the stanzas are read with, for reading the rest
+// of them without allocating per line once the signature of the input, if
+// any, has been checked. Calls to Scan and Next can be mixed; the stanzas
+// either reads are gone for the other.
+func (pr *StanzaReader) Scanner() *Scanner {
+	if pr.scanner == nil {
+		pr.scanner = newScanner(pr.reader, pr.opts, pr.line)
+	}
 
-		if pr.opts.strict && !validFieldName(els[0]) {
-			// Validate the raw text ahead of the colon, so that names padded
-			// with whitespace ("Key : value") are caught too.
-			return nil, fmt.Errorf("%w: '%s'", ErrInvalidFieldName, els[0])
-		}
+	return pr.scanner
+}
 
-		// We'll go ahead and take off any leading spaces.
-		lastKey = strings.TrimSpace(els[0])
-		value := strings.TrimSpace(els[1])
+// locate fills in the position of the ParseErrors returned while decoding the
+// stanza last read, which only know the field they concern.
+func (pr *StanzaReader) locate(err error) error {
+	return locate(err, pr.Scanner().stanzas, pr.fieldLines)
+}
 
-		if seen != nil {
-			// Policy 5.1: field names are case-insensitive.
-			folded := strings.ToLower(lastKey)
-			if _, found := seen[folded]; found {
-				return nil, fmt.Errorf("%w: '%s'", ErrDuplicateField, lastKey)
-			}
-			seen[folded] = struct{}{}
+// locate fills in the position of the ParseErrors returned while decoding a
+// stanza, given its index and the lines its fields start on.
+func locate(err error, stanza int, fieldLines map[string]int) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			locate(err, stanza, fieldLines)
 		}
 
-		haveField = true
+		return err
+	}
 
-		paragraph.Set(lastKey, value)
+	var perr *ParseError
+	if errors.As(err, &perr) && perr.Line == 0 && perr.Stanza == 0 {
+		perr.Line = fieldLines[perr.Field]
+		perr.Stanza = stanza
 	}
+
+	return err
 }
 
 // Internal method to read an OpenPGP Clearsigned document, store related
@@ -241,8 +257,9 @@ func (pr *StanzaReader) decodeClearsig(keyring openpgp.EntityList) error {
 	// One *massive* downside here is that the OpenPGP module in Go operates
 	// on byte arrays in memory, and *not* on Readers and Writers. This is a
 	// huge PITA because it doesn't need to be that way, and this forces
-	// clearsigned documents into memory. Which fucking sucks. But here
-	// we are. It's likely worth a bug or two on this.
+	// clearsigned documents into memory. WithStreamingVerification avoids
+	// this with a reader of our own (see clearsignReader), at the price of
+	// handing out stanzas before their signature has been checked.
 
 	signedData, err := io.ReadAll(pr.reader)
 	if err != nil {
@@ -260,20 +277,20 @@ func (pr *StanzaReader) decodeClearsig(keyring openpgp.EntityList) error {
 	}
 
 	// Now, we have to go ahead and check that the signature is valid and
-	// relates to an entity we have in our keyring
-	signer, err := openpgp.CheckDetachedSignature(
-		keyring,
-		bytes.NewReader(block.Bytes),
-		block.ArmoredSignature.Body,
-		nil,
-	)
-
+	// relates to an entity we have in our keyring. The signed text is the
+	// detached signature's document; reading it to the end checks it.
+	detached, err := newDetachedReader(bytes.NewReader(block.Bytes), block.ArmoredSignature.Body, keyring, pr.opts)
 	if err != nil {
 		return err
 	}
 
-	pr.signer = signer
+	if _, err := io.Copy(io.Discard, detached); err != nil {
+		return err
+	}
+
+	pr.reports = detached.reports
 	pr.reader = bufio.NewReader(bytes.NewBuffer(block.Bytes))
+	pr.line = clearsignHeaderLines(signedData)
 
 	return nil
 }
@@ -32,11 +32,18 @@
 package deb822_test
 
 import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"io"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/clearsign"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
 	"github.com/stretchr/testify/require"
 	"oaklab.hu/debian/deb822"
 )
@@ -56,6 +63,24 @@ Para: three
 	require.Len(t, blocks, 3)
 }
 
+func TestStanzas(t *testing.T) {
+	reader, err := deb822.NewStanzaReader(strings.NewReader(`Para: one
+
+Para: two
+
+Para: three
+`), nil)
+	require.NoError(t, err)
+
+	var paras []string
+	for stanza, err := range reader.Stanzas() {
+		require.NoError(t, err)
+		paras = append(paras, stanza.Values["Para"])
+	}
+
+	require.Equal(t, []string{"one", "two", "three"}, paras)
+}
+
 func TestMultipleNewlines(t *testing.T) {
 	reader, err := deb822.NewStanzaReader(strings.NewReader(`Para: one
 
@@ -156,3 +181,186 @@ func TestEmptyKeyringOpenPGPStanzaReader(t *testing.T) {
 	_, err = deb822.NewStanzaReader(f, keyring)
 	require.Error(t, err)
 }
+
+func TestStreamingVerification(t *testing.T) {
+	keyringFile, err := os.Open("testdata/archive-key-12.asc")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, keyringFile.Close())
+	})
+
+	keyring, err := openpgp.ReadArmoredKeyRing(keyringFile)
+	require.NoError(t, err)
+
+	inRelease, err := os.ReadFile("testdata/InRelease")
+	require.NoError(t, err)
+
+	t.Run("matches the buffered reader", func(t *testing.T) {
+		buffered, err := deb822.NewStanzaReader(bytes.NewReader(inRelease), keyring)
+		require.NoError(t, err)
+
+		expected, err := buffered.All()
+		require.NoError(t, err)
+
+		streaming, err := deb822.NewStanzaReader(bytes.NewReader(inRelease), keyring, deb822.WithStreamingVerification())
+		require.NoError(t, err)
+		require.Nil(t, streaming.Signer())
+
+		blocks, err := streaming.All()
+		require.NoError(t, err)
+		require.Equal(t, expected, blocks)
+		require.Equal(t, buffered.Signer(), streaming.Signer())
+	})
+
+	t.Run("empty keyring fails on the last read", func(t *testing.T) {
+		reader, err := deb822.NewStanzaReader(bytes.NewReader(inRelease), openpgp.EntityList{}, deb822.WithStreamingVerification())
+		require.NoError(t, err)
+
+		_, err = reader.All()
+		require.Error(t, err)
+		require.Nil(t, reader.Signer())
+	})
+
+	t.Run("truncated signature", func(t *testing.T) {
+		truncated := inRelease[:bytes.Index(inRelease, []byte("-----BEGIN PGP SIGNATURE-----"))]
+
+		reader, err := deb822.NewStanzaReader(bytes.NewReader(truncated), keyring, deb822.WithStreamingVerification())
+		require.NoError(t, err)
+
+		_, err = reader.All()
+		require.ErrorIs(t, err, deb822.ErrIncompleteClearsign)
+	})
+}
+
+func TestStreamingVerificationTampered(t *testing.T) {
+	entity, err := openpgp.NewEntity("test", "", "", &packet.Config{RSABits: 1024}) // insecure for testing
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	encoder, err := deb822.NewEncoder(&sb, entity)
+	require.NoError(t, err)
+	require.NoError(t, encoder.Encode(struct{ Package string }{"hello"}))
+	require.NoError(t, encoder.Encode(struct{ Package string }{"world"}))
+	require.NoError(t, encoder.Close())
+
+	signed := sb.String()
+	keyring := openpgp.EntityList{entity}
+
+	reader, err := deb822.NewStanzaReader(strings.NewReader(signed), keyring, deb822.WithStreamingVerification())
+	require.NoError(t, err)
+
+	blocks, err := reader.All()
+	require.NoError(t, err)
+	require.Len(t, blocks, 2)
+	require.Equal(t, entity, reader.Signer())
+
+	reader, err = deb822.NewStanzaReader(
+		strings.NewReader(strings.Replace(signed, "Package: world", "Package: wOrld", 1)),
+		keyring,
+		deb822.WithStreamingVerification(),
+	)
+	require.NoError(t, err)
+
+	// The stanzas themselves are handed out before the signature is reached.
+	for _, name := range []string{"hello", "wOrld"} {
+		stanza, err := reader.Next()
+		require.NoError(t, err)
+		require.Equal(t, name, stanza.Values["Package"])
+	}
+
+	_, err = reader.Next()
+	require.Error(t, err)
+	require.NotErrorIs(t, err, io.EOF)
+	require.Nil(t, reader.Signer())
+}
+
+func TestStreamingVerificationWhitespace(t *testing.T) {
+	entity, err := openpgp.NewEntity("test", "", "", &packet.Config{RSABits: 1024}) // insecure for testing
+	require.NoError(t, err)
+
+	keyring := openpgp.EntityList{entity}
+
+	// clearsign only trims spaces and tabs off the end of a line before
+	// hashing it; other whitespace is signed as it is.
+	for name, ending := range map[string]string{
+		"space and tab":      " \t",
+		"vertical tab":       "\v",
+		"form feed":          "\f",
+		"no-break space":     "\u00a0",
+		"mixed":              "\v \t",
+		"no-break and space": "\u00a0 ",
+	} {
+		t.Run(name, func(t *testing.T) {
+			var signed bytes.Buffer
+			w, err := clearsign.Encode(&signed, entity.PrivateKey, nil)
+			require.NoError(t, err)
+			_, err = io.WriteString(w, "Package: hello"+ending+"\nDescription: a greeting"+ending+"\n more"+ending+"\n")
+			require.NoError(t, err)
+			require.NoError(t, w.Close())
+
+			buffered, err := deb822.NewStanzaReader(bytes.NewReader(signed.Bytes()), keyring)
+			require.NoError(t, err)
+
+			expected, err := buffered.All()
+			require.NoError(t, err)
+
+			streaming, err := deb822.NewStanzaReader(bytes.NewReader(signed.Bytes()), keyring, deb822.WithStreamingVerification())
+			require.NoError(t, err)
+
+			blocks, err := streaming.All()
+			require.NoError(t, err)
+			require.Equal(t, expected, blocks)
+			require.Equal(t, entity, streaming.Signer())
+		})
+	}
+}
+
+func TestSignatureReport(t *testing.T) {
+	keyringFile, err := os.Open("testdata/archive-key-12.asc")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, keyringFile.Close())
+	})
+
+	keyring, err := openpgp.ReadArmoredKeyRing(keyringFile)
+	require.NoError(t, err)
+
+	inRelease, err := os.ReadFile("testdata/InRelease")
+	require.NoError(t, err)
+
+	for name, opts := range map[string][]deb822.ReaderOption{
+		"buffered":  nil,
+		"streaming": {deb822.WithStreamingVerification()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			decoder, err := deb822.NewDecoder(bytes.NewReader(inRelease), keyring, opts...)
+			require.NoError(t, err)
+
+			var release struct{ Origin string }
+			require.NoError(t, decoder.Decode(&release))
+			require.ErrorIs(t, decoder.Decode(&release), io.EOF)
+
+			report := decoder.SignatureReport()
+			require.NotNil(t, report)
+			require.Equal(t, decoder.Signer(), report.Signer)
+
+			// The archive key signs with a subkey.
+			require.Equal(t, "4CB50190207B4758A3F73A796ED0E7B82643E131", fmt.Sprintf("%X", report.Fingerprint))
+			require.NotEqual(t, report.Signer.PrimaryKey.Fingerprint, report.Fingerprint)
+			require.Equal(t, crypto.SHA256, report.Hash)
+			require.Equal(t, time.Date(2024, time.February, 10, 11, 8, 12, 0, time.UTC), report.CreationTime.UTC())
+
+			require.False(t, report.KeyExpired(report.CreationTime))
+			require.True(t, report.KeyExpired(time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC)))
+			require.False(t, report.Revoked(report.CreationTime))
+			require.False(t, report.SignatureExpired(report.CreationTime))
+		})
+	}
+
+	t.Run("unsigned", func(t *testing.T) {
+		reader, err := deb822.NewStanzaReader(strings.NewReader("Origin: Debian\n"), keyring)
+		require.NoError(t, err)
+		require.Nil(t, reader.SignatureReport())
+		require.Nil(t, reader.Signer())
+	})
+}
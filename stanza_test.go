@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
+)
+
+func TestStanza(t *testing.T) {
+	input := "Package: hello\nVersion: 2.10-3\nArchitecture: amd64\nDepends: libc6 (>= 2.34)\n"
+
+	requireConsistent := func(t *testing.T, s deb822.Stanza) {
+		t.Helper()
+
+		require.Len(t, s.Values, len(s.Order))
+		for _, field := range s.Order {
+			require.Contains(t, s.Values, field)
+		}
+	}
+
+	t.Run("Get and Has", func(t *testing.T) {
+		s := stanza(t, input)
+
+		value, found := s.Get("version")
+		require.True(t, found)
+		require.Equal(t, "2.10-3", value)
+
+		_, found = s.Get("Homepage")
+		require.False(t, found)
+
+		require.True(t, s.Has("DEPENDS"))
+		require.False(t, s.Has("Pre-Depends"))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		s := stanza(t, input)
+
+		require.True(t, s.Delete("architecture"))
+		require.False(t, s.Delete("Architecture"))
+		require.Equal(t, []string{"Package", "Version", "Depends"}, s.Order)
+		requireConsistent(t, s)
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		s := stanza(t, input)
+
+		require.True(t, s.Rename("depends", "Pre-Depends"))
+		require.Equal(t, []string{"Package", "Version", "Architecture", "Pre-Depends"}, s.Order)
+		require.Equal(t, "libc6 (>= 2.34)", s.Values["Pre-Depends"])
+
+		// A change of case only.
+		require.True(t, s.Rename("package", "PACKAGE"))
+		require.Equal(t, "PACKAGE", s.Order[0])
+
+		// Onto another field, which gives way.
+		require.True(t, s.Rename("Version", "architecture"))
+		require.Equal(t, []string{"PACKAGE", "architecture", "Pre-Depends"}, s.Order)
+		require.Equal(t, "2.10-3", s.Values["architecture"])
+
+		require.False(t, s.Rename("Homepage", "Url"))
+		requireConsistent(t, s)
+	})
+
+	t.Run("InsertBefore and InsertAfter", func(t *testing.T) {
+		s := stanza(t, input)
+
+		require.True(t, s.InsertBefore("package", "Source", "hello"))
+		require.True(t, s.InsertAfter("version", "Multi-Arch", "foreign"))
+		require.True(t, s.InsertAfter("Depends", "Homepage", "https://www.gnu.org/software/hello/"))
+		require.Equal(t, []string{"Source", "Package", "Version", "Multi-Arch", "Architecture", "Depends", "Homepage"}, s.Order)
+
+		// An existing field moves, and takes the new value.
+		require.True(t, s.InsertBefore("Version", "homepage", "https://example.org/"))
+		require.Equal(t, []string{"Source", "Package", "homepage", "Version", "Multi-Arch", "Architecture", "Depends"}, s.Order)
+
+		// A field that is its own mark stays in place.
+		require.True(t, s.InsertAfter("Version", "VERSION", "2.10-4"))
+		require.Equal(t, "2.10-4", s.Values["Version"])
+
+		require.False(t, s.InsertAfter("Description", "Section", "devel"))
+		require.False(t, s.Has("Section"))
+		requireConsistent(t, s)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		var s deb822.Stanza
+		s.Set("Package", "a")
+		s.Set("package", "b")
+
+		require.Equal(t, []string{"Package"}, s.Order)
+		require.Equal(t, "b", s.Values["Package"])
+
+		for _, name := range []string{"Package", "package", "PACKAGE"} {
+			value, found := s.Get(name)
+			require.True(t, found)
+			require.Equal(t, "b", value)
+		}
+
+		require.True(t, s.Delete("pAcKaGe"))
+		require.False(t, s.Has("Package"))
+		require.Empty(t, s.Order)
+		requireConsistent(t, s)
+	})
+
+	t.Run("Values and Order out of step", func(t *testing.T) {
+		s := deb822.Stanza{Values: map[string]string{"A": "1", "B": "2"}, Order: []string{"B"}}
+		require.True(t, s.Rename("A", "C"))
+		require.Equal(t, []string{"B", "C"}, s.Order)
+		requireConsistent(t, s)
+
+		s = deb822.Stanza{Values: map[string]string{"A": "1"}}
+		require.True(t, s.InsertBefore("A", "B", "2"))
+		require.Equal(t, []string{"B", "A"}, s.Order)
+		requireConsistent(t, s)
+
+		s = deb822.Stanza{Values: map[string]string{"A": "1"}}
+		s.Set("a", "2")
+		require.Equal(t, []string{"A"}, s.Order)
+		require.Equal(t, "2", s.Values["A"])
+		// No field of Values is lost, whatever Order lacks.
+		s = deb822.Stanza{Values: map[string]string{"A": "1", "B": "2"}}
+		require.True(t, s.Rename("b", "C"))
+		require.Equal(t, []string{"A", "C"}, s.Order)
+		requireConsistent(t, s)
+
+		// Nor does a name Order lists but Values lacks count.
+		s = deb822.Stanza{Values: map[string]string{}, Order: []string{"Ghost"}}
+		require.False(t, s.Has("ghost"))
+		_, found := s.Get("Ghost")
+		require.False(t, found)
+		require.False(t, s.Rename("ghost", "Spirit"))
+
+		s = deb822.Stanza{Values: map[string]string{"A": "1"}, Order: []string{"Ghost", "A"}}
+		require.True(t, s.InsertAfter("a", "B", "2"))
+		require.Equal(t, []string{"A", "B"}, s.Order)
+		requireConsistent(t, s)
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		s := stanza(t, input)
+		clone := s.Clone()
+
+		require.True(t, clone.Delete("Version"))
+		clone.Set("Package", "goodbye")
+
+		require.Equal(t, stanza(t, input), s)
+
+		var empty deb822.Stanza
+		clone = empty.Clone()
+		clone.Set("Package", "hello")
+		require.Nil(t, empty.Values)
+	})
+}
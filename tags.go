@@ -21,12 +21,19 @@ import (
 //
 // The grammar is:
 //
-//	debian:"Field-Name[,omitempty][,inline]"
+//	debian:"Field-Name[,omitempty][,inline][,required][,list=comma|space|line]"
+//	debian:",rest"
 //	debian:"-"
 //
-// A tag of "-" skips the field in both directions. An empty name part keeps
-// the fallback name (see lookupFieldTag). Unknown options are ignored, so new
-// options can be added without breaking older structs.
+// A tag of "-" skips the field in both directions. The rest option marks a
+// map[string]string or Stanza field as the home of every stanza field that no
+// other struct field takes (see unmarshalStanza and marshalStruct). The
+// required option fails both directions with ErrMissingField when the field
+// has no value. The list option carries a plain slice as a comma, space or
+// newline delimited list, the way the types of the list package do (see
+// marshalList). An empty name part keeps the fallback name (see
+// lookupFieldTag). Unknown options are ignored, so new options can be added
+// without breaking older structs.
 const TagKey = "debian"
 
 // jsonTagKey is the fallback struct tag. Only its name part is honoured on the
@@ -49,6 +56,12 @@ type tagOptions struct {
 	// inline flattens a named struct field into the surrounding stanza, the
 	// way an anonymous embedded struct is flattened.
 	inline bool
+	// rest makes the field the catch-all for unmatched stanza fields.
+	rest bool
+	// required makes a missing value an error, on decode and encode alike.
+	required bool
+	// list is the delimiter of a plain slice: comma, space or line.
+	list string
 }
 
 // parseTag splits a struct tag into its name part and its options.
@@ -66,6 +79,14 @@ func parseTag(tag string) (string, tagOptions) {
 			opts.omitEmpty = true
 		case "inline":
 			opts.inline = true
+		case "rest":
+			opts.rest = true
+		case "required":
+			opts.required = true
+		default:
+			if list, found := strings.CutPrefix(opt, "list="); found {
+				opts.list = list
+			}
 		}
 	}
 
@@ -125,6 +146,13 @@ type fieldInfo struct {
 	depth int
 	// omitEmpty reports whether the field carried the omitempty option.
 	omitEmpty bool
+	// rest reports whether the field is the catch-all for unmatched stanza
+	// fields. Its name is empty.
+	rest bool
+	// required reports whether the field carried the required option.
+	required bool
+	// list is the value of the list option, if the field carried one.
+	list string
 }
 
 // structInfo is the resolved field table of a struct type.
@@ -135,6 +163,10 @@ type structInfo struct {
 	fields []fieldInfo
 	// byName maps a lower cased field name to an index into fields.
 	byName map[string]int
+	// rest is the index into fields of the catch-all field, or -1.
+	rest int
+	// required reports whether any field carries the required option.
+	required bool
 }
 
 // structInfoCache memoises the field table of every struct type walked, keyed
@@ -159,7 +191,8 @@ func cachedStructInfo(t reflect.Type) *structInfo {
 // Name collisions are resolved the way Go resolves promoted fields: the
 // shallowest field wins. Unlike encoding/json a collision at equal depth is
 // not dropped, the first field in declaration order wins, so the result is
-// always deterministic.
+// always deterministic. The same goes for fields carrying the rest option, of
+// which only one is kept.
 func newStructInfo(t reflect.Type) *structInfo {
 	var collected []fieldInfo
 	collectFields(t, nil, map[reflect.Type]bool{t: true}, &collected)
@@ -173,14 +206,21 @@ func newStructInfo(t reflect.Type) *structInfo {
 		winner[collected[i].lowerName] = i
 	}
 
-	info := &structInfo{byName: make(map[string]int, len(winner))}
+	info := &structInfo{byName: make(map[string]int, len(winner)), rest: -1}
 
 	for i := range collected {
 		if winner[collected[i].lowerName] != i {
 			continue
 		}
 
-		info.byName[collected[i].lowerName] = len(info.fields)
+		info.required = info.required || collected[i].required
+
+		if collected[i].rest {
+			info.rest = len(info.fields)
+		} else {
+			info.byName[collected[i].lowerName] = len(info.fields)
+		}
+
 		info.fields = append(info.fields, collected[i])
 	}
 
@@ -235,6 +275,14 @@ func collectFields(t reflect.Type, index []int, visiting map[reflect.Type]bool,
 			continue
 		}
 
+		if opts.rest {
+			// The catch-all goes by no name, so the winner among several is
+			// picked like among fields that share one.
+			*out = append(*out, fieldInfo{index: fieldIndex, depth: len(fieldIndex) - 1, rest: true})
+
+			continue
+		}
+
 		if name == "" {
 			name = sf.Name
 		}
@@ -245,6 +293,8 @@ func collectFields(t reflect.Type, index []int, visiting map[reflect.Type]bool,
 			index:     fieldIndex,
 			depth:     len(fieldIndex) - 1,
 			omitEmpty: opts.omitEmpty,
+			required:  opts.required,
+			list:      opts.list,
 		})
 	}
 }
@@ -20,14 +20,17 @@ import (
 // Changes is a Debian changes file, as described by deb-changes(5). It is the
 // single stanza of a .changes file, which is usually OpenPGP clearsigned, and
 // it describes one upload: which files it carries and what changed in it.
+//
+// The fields Debian Policy 5.5 makes mandatory are required, except Binary,
+// which source-only uploads leave out.
 type Changes struct {
 	// Format is the changes file format version, such as "1.8".
-	Format string `debian:"Format" json:"Format"`
+	Format string `debian:"Format,required" json:"Format"`
 	// Date is the date the source package was built, taken from the top entry of the changelog.
-	Date time.Time `debian:"Date" json:"Date"`
+	Date time.Time `debian:"Date,required" json:"Date"`
 	// Source is the name of the source package, optionally followed by the source
 	// version in parentheses when it differs from the binary version.
-	Source string `debian:"Source" json:"Source"`
+	Source string `debian:"Source,required" json:"Source"`
 	// Binary lists the binary packages the upload carries. Note the separator:
 	// unlike the comma separated Binary field of a .dsc, the .changes one is
 	// space separated.
@@ -38,15 +41,15 @@ type Changes struct {
 	// "source", which marks an upload that includes the source package. It is
 	// not special cased here: as a single unknown token it parses into the
 	// ordinary tuple base-gnu-linux-source, and renders back as "source".
-	Architecture list.SpaceDelimited[arch.Arch] `debian:"Architecture,omitempty" json:"Architecture,omitzero"`
+	Architecture list.SpaceDelimited[arch.Arch] `debian:"Architecture,required" json:"Architecture,omitzero"`
 	// Version is the version of the package, with its epoch if it has one.
-	Version version.Version `debian:"Version" json:"Version"`
+	Version version.Version `debian:"Version,required" json:"Version"`
 	// Distribution lists the distributions the package is to be installed into.
-	Distribution list.SpaceDelimited[string] `debian:"Distribution" json:"Distribution"`
+	Distribution list.SpaceDelimited[string] `debian:"Distribution,required" json:"Distribution"`
 	// Urgency is the urgency of the upload, such as "low", "medium" or "high".
 	Urgency string `debian:"Urgency" json:"Urgency"`
 	// Maintainer is the name and email address of the person or organization responsible for the package.
-	Maintainer string `debian:"Maintainer" json:"Maintainer"`
+	Maintainer string `debian:"Maintainer,required" json:"Maintainer"`
 	// ChangedBy is the name and email address of the person who prepared this
 	// upload, which need not be the maintainer.
 	ChangedBy string `debian:"Changed-By,omitempty" json:"Changed-By,omitzero"`
@@ -57,12 +60,12 @@ type Changes struct {
 	Closes list.SpaceDelimited[string] `debian:"Closes,omitempty" json:"Closes,omitzero"`
 	// Changes is the changelog entries of this upload, verbatim, including the
 	// blank lines that a deb822 stanza carries as a lone "." on a line.
-	Changes string `debian:"Changes" json:"Changes"`
+	Changes string `debian:"Changes,required" json:"Changes"`
 	// ChecksumsSha1 lists the files of the upload with their SHA-1 checksums.
-	ChecksumsSha1 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha1,omitempty" json:"Checksums-Sha1,omitzero"`
+	ChecksumsSha1 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha1,required" json:"Checksums-Sha1,omitzero"`
 	// ChecksumsSha256 lists the files of the upload with their SHA-256 checksums.
-	ChecksumsSha256 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha256,omitempty" json:"Checksums-Sha256,omitzero"`
+	ChecksumsSha256 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha256,required" json:"Checksums-Sha256,omitzero"`
 	// Files lists the files of the upload with their MD5 checksums, archive
 	// section and priority.
-	Files list.NewLineDelimited[filehash.ChangesFileHash] `debian:"Files" json:"Files"`
+	Files list.NewLineDelimited[filehash.ChangesFileHash] `debian:"Files,required" json:"Files"`
 }
@@ -23,21 +23,23 @@ import (
 // A Dsc describes a source package as it was built by the packaging tools. The
 // archive publishes the same information, plus its own pool bookkeeping, as a
 // Source stanza in a Sources index.
+//
+// The fields Debian Policy 5.4 makes mandatory are required.
 type Dsc struct {
 	// Format is the source package format, such as "3.0 (quilt)".
-	Format string `debian:"Format" json:"Format"`
+	Format string `debian:"Format,required" json:"Format"`
 	// Source is the name of the source package.
-	Source string `debian:"Source" json:"Source"`
+	Source string `debian:"Source,required" json:"Source"`
 	// Binary lists the binary packages this source package builds.
 	Binary list.CommaDelimited[string] `debian:"Binary,omitempty" json:"Binary,omitzero"`
 	// Architecture lists the architectures the source package can be built for.
 	Architecture list.SpaceDelimited[arch.Arch] `debian:"Architecture,omitempty" json:"Architecture,omitzero"`
 	// Version is the version of the source package.
-	Version version.Version `debian:"Version" json:"Version"`
+	Version version.Version `debian:"Version,required" json:"Version"`
 	// Origin is the distribution the package originally came from.
 	Origin string `debian:"Origin,omitempty" json:"Origin,omitzero"`
 	// Maintainer is the name and email address of the person or organization responsible for the package.
-	Maintainer string `debian:"Maintainer" json:"Maintainer"`
+	Maintainer string `debian:"Maintainer,required" json:"Maintainer"`
 	// Uploaders lists co-maintainers allowed to upload the package.
 	Uploaders list.CommaDelimited[string] `debian:"Uploaders,omitempty" json:"Uploaders,omitzero"`
 	// Homepage is the URL of the upstream project's homepage.
@@ -82,11 +84,11 @@ type Dsc struct {
 	// each with its package type, section, priority and build profile fields.
 	PackageList list.NewLineDelimited[string] `debian:"Package-List,omitempty" json:"Package-List,omitzero"`
 	// ChecksumsSha1 lists the files of the source package with their SHA-1 checksums.
-	ChecksumsSha1 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha1,omitempty" json:"Checksums-Sha1,omitzero"`
+	ChecksumsSha1 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha1,required" json:"Checksums-Sha1,omitzero"`
 	// ChecksumsSha256 lists the files of the source package with their SHA-256 checksums.
-	ChecksumsSha256 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha256,omitempty" json:"Checksums-Sha256,omitzero"`
+	ChecksumsSha256 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha256,required" json:"Checksums-Sha256,omitzero"`
 	// ChecksumsSha512 lists the files of the source package with their SHA-512 checksums.
 	ChecksumsSha512 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha512,omitempty" json:"Checksums-Sha512,omitzero"`
 	// Files lists the files of the source package with their MD5 checksums.
-	Files list.NewLineDelimited[filehash.FileHash] `debian:"Files" json:"Files"`
+	Files list.NewLineDelimited[filehash.FileHash] `debian:"Files,required" json:"Files"`
 }
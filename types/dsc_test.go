@@ -10,6 +10,7 @@
 package types_test
 
 import (
+	"bytes"
 	"os"
 	"testing"
 
@@ -126,3 +127,25 @@ func TestDsc(t *testing.T) {
 		}, dsc.ChecksumsSha256[1])
 	})
 }
+
+// TestDscKnowsEveryField checks that types.Dsc has a home for every field of
+// a real .dsc, so that an upload gate can reject unexpected ones.
+func TestDscKnowsEveryField(t *testing.T) {
+	data, err := os.ReadFile("../testdata/0ad_0.0.26-3.dsc")
+	require.NoError(t, err)
+
+	keyringFile, err := os.Open("../testdata/d53a815a3cb7659af882e3958eedcc1baa1f32ff.asc")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, keyringFile.Close())
+	})
+
+	keyring, err := openpgp.ReadArmoredKeyRing(keyringFile)
+	require.NoError(t, err)
+
+	decoder, err := deb822.NewDecoder(bytes.NewReader(data), keyring, deb822.WithDisallowUnknownFields())
+	require.NoError(t, err)
+
+	var dsc types.Dsc
+	require.NoError(t, decoder.Decode(&dsc))
+}
@@ -10,48 +10,44 @@
 package list
 
 import (
-	"encoding"
-	"fmt"
 	"strings"
+
+	"oaklab.hu/debian/deb822"
 )
 
 // CommaDelimited is a list of T entries separated by commas.
 type CommaDelimited[T any] []T
 
 func (l CommaDelimited[T]) MarshalText() ([]byte, error) {
+	return l.MarshalTextWith(nil)
+}
+
+// MarshalTextWith renders the list as MarshalText does, rendering the entries
+// codecs has a codec for with it.
+func (l CommaDelimited[T]) MarshalTextWith(codecs *deb822.Codecs) ([]byte, error) {
 	var sb strings.Builder
 	for i, entry := range l {
 		if i > 0 {
 			sb.WriteString(", ")
 		}
 
-		switch v := any(entry).(type) {
-		case string:
-			sb.WriteString(v)
-		case encoding.TextMarshaler:
-			text, err := v.MarshalText()
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal entry: %w", err)
-			}
-			sb.Write(text)
-		default:
-			// Maybe the type has a pointer receiver for MarshalText?
-			if ptr, ok := any(&entry).(encoding.TextMarshaler); ok {
-				text, err := ptr.MarshalText()
-				if err != nil {
-					return nil, fmt.Errorf("failed to marshal entry: %w", err)
-				}
-				sb.Write(text)
-			} else {
-				sb.WriteString(fmt.Sprintf("%v", entry))
-			}
+		text, err := marshalEntry(entry, codecs)
+		if err != nil {
+			return nil, err
 		}
+		sb.Write(text)
 	}
 
 	return []byte(sb.String()), nil
 }
 
 func (l *CommaDelimited[T]) UnmarshalText(text []byte) error {
+	return l.UnmarshalTextWith(text, nil)
+}
+
+// UnmarshalTextWith parses the list as UnmarshalText does, parsing the entries
+// codecs has a codec for with it.
+func (l *CommaDelimited[T]) UnmarshalTextWith(text []byte, codecs *deb822.Codecs) error {
 	items := strings.Split(string(text), ",")
 	for _, item := range items {
 		item = strings.TrimSpace(item)
@@ -59,20 +55,9 @@ func (l *CommaDelimited[T]) UnmarshalText(text []byte) error {
 			continue
 		}
 
-		var entry T
-
-		switch v := any(&entry).(type) {
-		case *string:
-			*v = item
-		case encoding.TextUnmarshaler:
-			if err := v.UnmarshalText([]byte(item)); err != nil {
-				return fmt.Errorf("failed to unmarshal entry: %w", err)
-			}
-		default:
-			_, err := fmt.Sscanf(item, "%v", &entry)
-			if err != nil {
-				return fmt.Errorf("unable to unmarshal entry: %w", err)
-			}
+		entry, err := unmarshalEntry[T](item, codecs)
+		if err != nil {
+			return err
 		}
 
 		*l = append(*l, entry)
@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package list
+
+import (
+	"encoding"
+	"fmt"
+
+	"oaklab.hu/debian/deb822"
+)
+
+// marshalEntry renders a single entry of a list: with the codec codecs has
+// for its type, if there is one, otherwise as a string, with its MarshalText
+// method, or as fmt formats it.
+func marshalEntry[T any](entry T, codecs *deb822.Codecs) ([]byte, error) {
+	if text, ok, err := codecs.Marshal(entry); ok {
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal entry: %w", err)
+		}
+
+		return []byte(text), nil
+	}
+
+	switch v := any(entry).(type) {
+	case string:
+		return []byte(v), nil
+	case encoding.TextMarshaler:
+		text, err := v.MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal entry: %w", err)
+		}
+
+		return text, nil
+	}
+
+	// Maybe the type has a pointer receiver for MarshalText?
+	if ptr, ok := any(&entry).(encoding.TextMarshaler); ok {
+		text, err := ptr.MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal entry: %w", err)
+		}
+
+		return text, nil
+	}
+
+	return []byte(fmt.Sprintf("%v", entry)), nil
+}
+
+// unmarshalEntry parses a single entry of a list, in the same order of
+// precedence as marshalEntry.
+func unmarshalEntry[T any](item string, codecs *deb822.Codecs) (T, error) {
+	var entry T
+
+	if ok, err := codecs.Unmarshal(item, &entry); ok {
+		if err != nil {
+			return entry, fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+
+		return entry, nil
+	}
+
+	switch v := any(&entry).(type) {
+	case *string:
+		*v = item
+	case encoding.TextUnmarshaler:
+		if err := v.UnmarshalText([]byte(item)); err != nil {
+			return entry, fmt.Errorf("failed to unmarshal entry: %w", err)
+		}
+	default:
+		_, err := fmt.Sscanf(item, "%v", &entry)
+		if err != nil {
+			return entry, fmt.Errorf("unable to unmarshal entry: %w", err)
+		}
+	}
+
+	return entry, nil
+}
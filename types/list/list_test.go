@@ -11,9 +11,11 @@ package list_test
 
 import (
 	"math/big"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"oaklab.hu/debian/deb822"
 	"oaklab.hu/debian/deb822/types/list"
 )
 
@@ -201,3 +203,41 @@ func TestSpaceDelimited(t *testing.T) {
 		})
 	})
 }
+
+func TestCodecs(t *testing.T) {
+	var codecs deb822.Codecs
+	deb822.RegisterCodec(&codecs, func(u url.URL) (string, error) {
+		return u.String(), nil
+	}, func(text string) (url.URL, error) {
+		u, err := url.Parse(text)
+		if err != nil {
+			return url.URL{}, err
+		}
+
+		return *u, nil
+	})
+
+	t.Run("MarshalTextWith", func(t *testing.T) {
+		l := list.CommaDelimited[url.URL]{{Scheme: "https", Host: "deb.debian.org"}, {Scheme: "http", Host: "ftp.debian.org"}}
+
+		text, err := l.MarshalTextWith(&codecs)
+		require.NoError(t, err)
+
+		require.Equal(t, "https://deb.debian.org, http://ftp.debian.org", string(text))
+	})
+
+	t.Run("UnmarshalTextWith", func(t *testing.T) {
+		var l list.NewLineDelimited[url.URL]
+		err := l.UnmarshalTextWith([]byte("\n https://deb.debian.org\n http://ftp.debian.org"), &codecs)
+		require.NoError(t, err)
+
+		require.Equal(t, list.NewLineDelimited[url.URL]{{Scheme: "https", Host: "deb.debian.org"}, {Scheme: "http", Host: "ftp.debian.org"}}, l)
+	})
+
+	t.Run("Without codecs", func(t *testing.T) {
+		var l list.SpaceDelimited[int]
+		require.NoError(t, l.UnmarshalTextWith([]byte("1 2 3"), nil))
+
+		require.Equal(t, list.SpaceDelimited[int]{1, 2, 3}, l)
+	})
+}
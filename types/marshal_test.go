@@ -86,10 +86,10 @@ func TestDecodeIsCaseInsensitive(t *testing.T) {
 		name  string
 		input string
 	}{
-		{name: "as declared", input: "Package: foo\nVersion: 1.0-1\n"},
-		{name: "lower case", input: "package: foo\nversion: 1.0-1\n"},
-		{name: "upper case", input: "PACKAGE: foo\nVERSION: 1.0-1\n"},
-		{name: "mixed case", input: "packAGE: foo\nvErSiOn: 1.0-1\n"},
+		{name: "as declared", input: "Package: foo\nVersion: 1.0-1\nArchitecture: all\n"},
+		{name: "lower case", input: "package: foo\nversion: 1.0-1\narchitecture: all\n"},
+		{name: "upper case", input: "PACKAGE: foo\nVERSION: 1.0-1\nARCHITECTURE: all\n"},
+		{name: "mixed case", input: "packAGE: foo\nvErSiOn: 1.0-1\narChiTecture: all\n"},
 	}
 
 	for _, test := range tests {
@@ -23,19 +23,24 @@ import (
 )
 
 // Package represents a Debian package with all its metadata fields.
+//
+// Package, Version and Architecture are required. Debian Policy 5.3 makes
+// Maintainer and Description mandatory in a binary package's control file as
+// well, but Packages indices carry Description-md5 in place of the description,
+// so neither is enforced here.
 type Package struct {
 	// Name is the name of the binary package.
-	Name string `debian:"Package" json:"Package"`
+	Name string `debian:"Package,required" json:"Package"`
 	// Source is the name of the source package from which this package is built.
 	Source *dependency.Source `debian:"Source,omitempty" json:"Source,omitzero"`
 	// Version is the version of the package.
-	Version version.Version `debian:"Version" json:"Version"`
+	Version version.Version `debian:"Version,required" json:"Version"`
 	// InstalledSize is the estimated installed size of the package, in kilobytes.
 	InstalledSize *int `debian:"Installed-Size,omitempty" json:"Installed-Size,omitzero"`
 	// Maintainer is the name and email address of the person or organization responsible for the package.
 	Maintainer string `debian:"Maintainer,omitempty" json:"Maintainer,omitzero"`
 	// Architecture is the Debian machine architecture the package is built for.
-	Architecture arch.Arch `debian:"Architecture" json:"Architecture"`
+	Architecture arch.Arch `debian:"Architecture,required" json:"Architecture"`
 	// ArchitectureVariant is an optional field that specifies a variant of the architecture, such as amd64v3 for AMD64 with AVX-512 support.
 	// This field is used to distinguish between different variants of the same architecture.
 	ArchitectureVariant string `debian:"Architecture-Variant,omitempty" json:"Architecture-Variant,omitzero"`
@@ -28,23 +28,27 @@ import (
 // *binary* package stanza - a name, optionally with a version in parentheses,
 // pointing at the source package a binary was built from. A Package carries the
 // latter in its Source field; a Sources index carries the former.
+//
+// The fields the archive writes for every source package are required: those
+// Debian Policy 5.4 makes mandatory in a .dsc, bar the checksums older indices
+// lack, plus Directory.
 type Source struct {
 	// Package is the name of the source package.
-	Package string `debian:"Package" json:"Package"`
+	Package string `debian:"Package,required" json:"Package"`
 	// Format is the source package format, such as "3.0 (quilt)".
-	Format string `debian:"Format" json:"Format"`
+	Format string `debian:"Format,required" json:"Format"`
 	// Binary lists the binary packages this source package builds.
 	Binary list.CommaDelimited[string] `debian:"Binary,omitempty" json:"Binary,omitzero"`
 	// Architecture lists the architectures the source package can be built for.
 	Architecture list.SpaceDelimited[arch.Arch] `debian:"Architecture,omitempty" json:"Architecture,omitzero"`
 	// Version is the version of the source package.
-	Version version.Version `debian:"Version" json:"Version"`
+	Version version.Version `debian:"Version,required" json:"Version"`
 	// Priority is the default priority of the binary packages built from this source.
 	Priority string `debian:"Priority,omitempty" json:"Priority,omitzero"`
 	// Section is the default archive section of the binary packages built from this source.
 	Section string `debian:"Section,omitempty" json:"Section,omitzero"`
 	// Maintainer is the name and email address of the person or organization responsible for the package.
-	Maintainer string `debian:"Maintainer" json:"Maintainer"`
+	Maintainer string `debian:"Maintainer,required" json:"Maintainer"`
 	// Uploaders lists co-maintainers allowed to upload the package.
 	Uploaders list.CommaDelimited[string] `debian:"Uploaders,omitempty" json:"Uploaders,omitzero"`
 	// OriginalMaintainer records the maintainer of the package before a derivative distribution took it over.
@@ -95,12 +99,12 @@ type Source struct {
 	ExtraSourceOnly *boolean.Boolean `debian:"Extra-Source-Only,omitempty" json:"Extra-Source-Only,omitzero"`
 	// Directory is the pool directory holding the files of the source package,
 	// relative to the root of the repository.
-	Directory string `debian:"Directory" json:"Directory"`
+	Directory string `debian:"Directory,required" json:"Directory"`
 	// PackageList lists the binary packages built from this source, one per line,
 	// each with its package type, section, priority and build profile fields.
 	PackageList list.NewLineDelimited[string] `debian:"Package-List,omitempty" json:"Package-List,omitzero"`
 	// Files lists the files of the source package with their MD5 checksums.
-	Files list.NewLineDelimited[filehash.FileHash] `debian:"Files,omitempty" json:"Files,omitzero"`
+	Files list.NewLineDelimited[filehash.FileHash] `debian:"Files,required" json:"Files,omitzero"`
 	// ChecksumsSha1 lists the files of the source package with their SHA-1 checksums.
 	ChecksumsSha1 list.NewLineDelimited[filehash.FileHash] `debian:"Checksums-Sha1,omitempty" json:"Checksums-Sha1,omitzero"`
 	// ChecksumsSha256 lists the files of the source package with their SHA-256 checksums.
@@ -0,0 +1,395 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2026 Kristof Bach <crys@crys.hu>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package deb822
+
+import (
+	"crypto"
+	"encoding"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+)
+
+// candidateHashes are the digests computed over a clearsigned body that does
+// not announce its hash algorithm in a Hash armor header. The signature
+// packet that names the algorithm only arrives after the body, so a streaming
+// reader has to keep every plausible digest running until then.
+var candidateHashes = []crypto.Hash{
+	crypto.SHA256,
+	crypto.SHA512,
+	crypto.SHA384,
+	crypto.SHA224,
+	crypto.SHA1,
+	crypto.SHA3_256,
+	crypto.SHA3_512,
+}
+
+// messageHashes keeps one running digest per hash algorithm over the signed
+// text, so that a signature can be checked once its packet has been read.
+type messageHashes struct {
+	hashes map[crypto.Hash]hash.Hash
+	writer io.Writer
+}
+
+// newMessageHashes starts a digest for each of the given algorithms. Those the
+// binary was not built with are skipped; a signature using one of them then
+// fails to verify like any other unsupported signature.
+func newMessageHashes(algorithms []crypto.Hash) *messageHashes {
+	m := &messageHashes{hashes: make(map[crypto.Hash]hash.Hash, len(algorithms))}
+
+	writers := make([]io.Writer, 0, len(algorithms))
+	for _, algorithm := range algorithms {
+		if _, found := m.hashes[algorithm]; found || !algorithm.Available() {
+			continue
+		}
+
+		h := algorithm.New()
+		m.hashes[algorithm] = h
+		writers = append(writers, h)
+	}
+
+	m.writer = io.MultiWriter(writers...)
+
+	return m
+}
+
+// Write feeds signed text to every running digest.
+func (m *messageHashes) Write(p []byte) (int, error) {
+	return m.writer.Write(p)
+}
+
+// SignatureReport describes a signature that has been verified, with enough
+// detail to apply a policy on top of the cryptographic check, such as
+// rejecting SHA-1 signatures or signatures older than a Release file's
+// Valid-Until.
+type SignatureReport struct {
+	// Signer is the entity that made the signature.
+	Signer *openpgp.Entity
+	// Key is the key of Signer, primary or subkey, the signature was made with.
+	Key openpgp.Key
+	// Signature is the signature packet itself.
+	Signature *packet.Signature
+
+	// Fingerprint is the fingerprint of Key, which is a subkey fingerprint
+	// when a signing subkey was used.
+	Fingerprint []byte
+	// KeyID is the key ID of Key.
+	KeyID uint64
+	// CreationTime is when the signature was made, as claimed by the signer.
+	CreationTime time.Time
+	// Hash is the digest algorithm the signature was made over.
+	Hash crypto.Hash
+}
+
+// newSignatureReport describes a signature sig made with key.
+func newSignatureReport(key openpgp.Key, sig *packet.Signature) *SignatureReport {
+	return &SignatureReport{
+		Signer:       key.Entity,
+		Key:          key,
+		Signature:    sig,
+		Fingerprint:  key.PublicKey.Fingerprint,
+		KeyID:        key.PublicKey.KeyId,
+		CreationTime: sig.CreationTime,
+		Hash:         sig.Hash,
+	}
+}
+
+// KeyExpired reports whether the signing key, or the primary key it belongs
+// to, has expired (or was not yet valid) at the given time.
+func (r *SignatureReport) KeyExpired(at time.Time) bool {
+	if selfSignature, _ := r.Signer.PrimarySelfSignature(); selfSignature != nil &&
+		r.Signer.PrimaryKey.KeyExpired(selfSignature, at) {
+		return true
+	}
+
+	if r.Key.PublicKey != r.Signer.PrimaryKey && r.Key.SelfSignature != nil {
+		return r.Key.PublicKey.KeyExpired(r.Key.SelfSignature, at)
+	}
+
+	return false
+}
+
+// Revoked reports whether the signing key, the primary key it belongs to, or
+// the primary identity has been revoked at the given time.
+func (r *SignatureReport) Revoked(at time.Time) bool {
+	if r.Signer.Revoked(at) {
+		return true
+	}
+
+	if r.Key.PublicKey != r.Signer.PrimaryKey && r.Key.Revoked(at) {
+		return true
+	}
+
+	_, primaryIdentity := r.Signer.PrimarySelfSignature()
+
+	return primaryIdentity != nil && primaryIdentity.Revoked(at)
+}
+
+// SignatureExpired reports whether the signature itself has expired (or was
+// not yet valid) at the given time.
+func (r *SignatureReport) SignatureExpired(at time.Time) bool {
+	return r.Signature.SigExpired(at)
+}
+
+// streamResult records the outcome of a signature check made by a reader once
+// it has been read to the end.
+type streamResult struct {
+	// reports describe the signatures that verified, once they have been
+	// checked.
+	reports []*SignatureReport
+}
+
+// signatureCheck is a signature packet, paired with the keys of the keyring
+// that could have made it and the digest of the text it is checked against.
+type signatureCheck struct {
+	sig  *packet.Signature
+	keys []openpgp.Key
+	// err, when set, fails the signature whatever the digest.
+	err error
+
+	// digest is set by whoever digests the signed text.
+	digest hash.Hash
+}
+
+// readSignatures reads the signature packets of a detached (or clearsigned)
+// signature and returns the ones to check.
+//
+// Without a policy that is only the first signature made by a key in keyring,
+// the one openpgp.CheckDetachedSignature would check. With a policy it is
+// every signature, including those by keys the keyring does not hold, so that
+// the policy gets to see all of them.
+//
+// Under WithSignedBy only the pinned keys of the keyring count. A signature
+// by another key of the keyring fails with ErrSignerNotPinned rather than as
+// one by an unknown issuer.
+func readSignatures(keyring openpgp.EntityList, signature io.Reader, opts readerOptions) ([]*signatureCheck, error) {
+	var (
+		checks   []*signatureCheck
+		unpinned error
+	)
+
+	packets := packet.NewReader(signature)
+
+	for {
+		p, err := packets.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		sig, ok := p.(*packet.Signature)
+		if !ok {
+			return nil, pgperrors.StructuralError("non signature packet found")
+		}
+
+		if sig.IssuerKeyId == nil {
+			return nil, pgperrors.StructuralError("signature doesn't have an issuer")
+		}
+
+		check := &signatureCheck{sig: sig, keys: keyring.KeysByIdUsage(*sig.IssuerKeyId, packet.KeyFlagSign)}
+
+		if len(opts.signedBy) > 0 && len(check.keys) > 0 {
+			if check.keys = pinnedKeys(check.keys, opts.signedBy); len(check.keys) == 0 {
+				check.err = fmt.Errorf("%w: %016X", ErrSignerNotPinned, *sig.IssuerKeyId)
+				unpinned = check.err
+			}
+		}
+
+		if opts.policy == nil {
+			if len(check.keys) > 0 {
+				return []*signatureCheck{check}, nil
+			}
+
+			continue
+		}
+
+		checks = append(checks, check)
+	}
+
+	if len(checks) == 0 {
+		if unpinned != nil {
+			return nil, unpinned
+		}
+
+		return nil, pgperrors.ErrUnknownIssuer
+	}
+
+	return checks, nil
+}
+
+// verify checks the signature against its digest and describes it.
+func (c *signatureCheck) verify() (*SignatureReport, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	if len(c.keys) == 0 {
+		return nil, pgperrors.ErrUnknownIssuer
+	}
+
+	var err error
+	for _, key := range c.keys {
+		if err = key.PublicKey.VerifySignature(c.digest, c.sig); err == nil {
+			if err := checkSignatureDetails(key, c.sig, time.Now()); err != nil {
+				return nil, err
+			}
+
+			return newSignatureReport(key, c.sig), nil
+		}
+	}
+
+	return nil, err
+}
+
+// verifySignatures checks every signature against its digest. Without a
+// policy the single signature has to verify; with one, the policy decides on
+// the outcome of all of them.
+func verifySignatures(checks []*signatureCheck, policy SignaturePolicy) ([]*SignatureReport, error) {
+	if policy == nil {
+		report, err := checks[0].verify()
+		if err != nil {
+			return nil, err
+		}
+
+		return []*SignatureReport{report}, nil
+	}
+
+	var (
+		reports  []*SignatureReport
+		failures []SignatureFailure
+	)
+
+	for _, check := range checks {
+		report, err := check.verify()
+		if err != nil {
+			failures = append(failures, SignatureFailure{Signature: check.sig, Err: err})
+			continue
+		}
+
+		reports = append(reports, report)
+	}
+
+	if err := policy(reports, failures); err != nil {
+		return nil, err
+	}
+
+	return reports, nil
+}
+
+// checkSignatures checks a clearsigned document's signatures against the
+// digests taken over its text.
+func checkSignatures(keyring openpgp.EntityList, signature io.Reader, hashes *messageHashes, opts readerOptions) ([]*SignatureReport, error) {
+	checks, err := readSignatures(keyring, signature, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, check := range checks {
+		if check.sig.Version == 6 {
+			// The salt of a v6 signature has to be hashed ahead of the text,
+			// which is impossible once the text has gone past.
+			return nil, pgperrors.UnsupportedError("salted signatures cannot be verified while streaming")
+		}
+
+		h, found := hashes.hashes[check.sig.Hash]
+		if !found {
+			return nil, pgperrors.StructuralError("hash algorithm mismatch with cleartext message headers")
+		}
+
+		// Checking a signature adds its trailer to the digest, so every
+		// signature gets a copy of its own.
+		if check.digest, err = cloneHash(check.sig.Hash, h); err != nil {
+			return nil, err
+		}
+	}
+
+	return verifySignatures(checks, opts.policy)
+}
+
+// cloneHash copies the state of a running digest.
+func cloneHash(algorithm crypto.Hash, h hash.Hash) (hash.Hash, error) {
+	marshaler, ok := h.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, pgperrors.UnsupportedError("hash state cannot be copied: " + algorithm.String())
+	}
+
+	state, err := marshaler.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	clone := algorithm.New()
+
+	unmarshaler, ok := clone.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return nil, pgperrors.UnsupportedError("hash state cannot be copied: " + algorithm.String())
+	}
+
+	if err := unmarshaler.UnmarshalBinary(state); err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
+
+// checkSignatureDetails rejects a cryptographically valid signature whose key
+// or binding signatures are no longer trustworthy at now: an unknown critical
+// notation, a revoked key or identity, an expired key, or an expired
+// signature. It mirrors the checks openpgp.CheckDetachedSignature performs,
+// which are not exported.
+func checkSignatureDetails(key openpgp.Key, sig *packet.Signature, now time.Time) error {
+	primarySelfSignature, primaryIdentity := key.Entity.PrimarySelfSignature()
+	signedBySubKey := key.PublicKey != key.Entity.PrimaryKey
+
+	sigsToCheck := []*packet.Signature{sig, primarySelfSignature}
+	if signedBySubKey && key.SelfSignature != nil {
+		sigsToCheck = append(sigsToCheck, key.SelfSignature, key.SelfSignature.EmbeddedSignature)
+	}
+
+	for _, s := range sigsToCheck {
+		if s == nil {
+			continue
+		}
+
+		for _, notation := range s.Notations {
+			if notation.IsCritical {
+				return pgperrors.SignatureError("unknown critical notation: " + notation.Name)
+			}
+		}
+	}
+
+	if key.Entity.Revoked(now) ||
+		(signedBySubKey && key.Revoked(now)) ||
+		(primaryIdentity != nil && primaryIdentity.Revoked(now)) {
+		return pgperrors.ErrKeyRevoked
+	}
+
+	if primarySelfSignature != nil && key.Entity.PrimaryKey.KeyExpired(primarySelfSignature, now) {
+		return pgperrors.ErrKeyExpired
+	}
+
+	if signedBySubKey && key.SelfSignature != nil && key.PublicKey.KeyExpired(key.SelfSignature, now) {
+		return pgperrors.ErrKeyExpired
+	}
+
+	for _, s := range sigsToCheck {
+		if s != nil && s.SigExpired(now) {
+			return pgperrors.ErrSignatureExpired
+		}
+	}
+
+	return nil
+}
@@ -14,8 +14,10 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // marshalStruct renders a struct into a Stanza.
@@ -31,7 +33,15 @@ import (
 //   - it carries the omitempty option and holds the zero value of its type.
 //     This is what keeps a numeric field whose zero renders as "0" out of the
 //     stanza.
-func marshalStruct(data reflect.Value) (*Stanza, error) {
+//
+// Leaving out a field that carries the required option fails with
+// ErrMissingField instead. Values are rendered with codecs where it has a
+// codec for their type.
+//
+// The contents of the catch-all field, if the struct has one, are written at
+// its position: in their original order for a Stanza, sorted by name for a
+// map. Those that another field of the struct is named after are left out.
+func marshalStruct(data reflect.Value, codecs *Codecs) (*Stanza, error) {
 	if data.Kind() != reflect.Struct {
 		return nil, errors.New("can only Encode a Struct")
 	}
@@ -45,19 +55,35 @@ func marshalStruct(data reflect.Value) (*Stanza, error) {
 
 		value, ok := fieldByIndex(data, field.index)
 		if !ok {
+			if field.required {
+				return nil, fmt.Errorf("failed to marshal field %q: %w", field.name, ErrMissingField)
+			}
+
+			continue
+		}
+
+		if field.rest {
+			if err := marshalRest(stanza, value, info); err != nil {
+				return nil, err
+			}
+
 			continue
 		}
 
-		if field.omitEmpty && isEmptyValue(value) {
+		if field.omitEmpty && isEmptyValue(value) && !field.required {
 			continue
 		}
 
-		text, ok, err := marshalFieldText(value)
+		text, ok, err := marshalField(value, field, codecs)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal field %q: %w", field.name, err)
 		}
 
 		if !ok || text == "" {
+			if field.required {
+				return nil, fmt.Errorf("failed to marshal field %q: %w", field.name, ErrMissingField)
+			}
+
 			continue
 		}
 
@@ -72,24 +98,50 @@ func marshalStruct(data reflect.Value) (*Stanza, error) {
 // unmarshalStanza fills a struct from a Stanza.
 //
 // Field names are matched case insensitively, as Debian Policy 5.1 requires.
-// Stanza fields the struct has no home for are ignored, and struct fields the
-// stanza does not mention are left untouched. Fields present with an empty
-// value are treated as absent.
-func unmarshalStanza(stanza Stanza, into reflect.Value) error {
+// Stanza fields the struct has no home for go to its catch-all field, if it
+// has one, and are otherwise ignored, unless WithDisallowUnknownFields is in
+// effect. Struct fields the stanza does not mention are left untouched, unless
+// they carry the required option, which fails with ErrMissingField. Fields
+// present with an empty value are treated as absent.
+func unmarshalStanza(stanza Stanza, into reflect.Value, opts readerOptions) error {
 	if into.Kind() != reflect.Struct {
 		return errors.New("can only Decode a Struct")
 	}
 
 	info := cachedStructInfo(into.Type())
 
+	var (
+		errs    []error
+		present []bool
+	)
+
+	if info.required {
+		present = make([]bool, len(info.fields))
+	}
+
 	for _, key := range stanza.Order {
-		text := stanza.Values[key]
-		if text == "" {
+		i, found := info.byName[strings.ToLower(key)]
+		if !found && info.rest >= 0 {
+			value, err := fieldByIndexAlloc(into, info.fields[info.rest].index)
+			if err != nil {
+				return &ParseError{Field: key, Err: err}
+			}
+
+			if err := unmarshalRest(value, key, stanza.Values[key]); err != nil {
+				return &ParseError{Field: key, Err: err}
+			}
+
+			continue
+		} else if !found {
+			if opts.disallowUnknownFields {
+				errs = append(errs, &ParseError{Field: key, Err: ErrUnknownField})
+			}
+
 			continue
 		}
 
-		i, found := info.byName[strings.ToLower(key)]
-		if !found {
+		text := stanza.Values[key]
+		if text == "" {
 			continue
 		}
 
@@ -97,17 +149,87 @@ func unmarshalStanza(stanza Stanza, into reflect.Value) error {
 
 		value, err := fieldByIndexAlloc(into, field.index)
 		if err != nil {
-			return fmt.Errorf("failed to unmarshal field %q: %w", key, err)
+			return &ParseError{Field: key, Err: err}
+		}
+
+		if err := unmarshalField(value, text, field, opts.codecs); err != nil {
+			return &ParseError{Field: key, Err: err}
+		}
+
+		if present != nil {
+			present[i] = true
+		}
+	}
+
+	for i := range present {
+		if info.fields[i].required && !present[i] {
+			errs = append(errs, &ParseError{Field: info.fields[i].name, Err: ErrMissingField})
+		}
+	}
+
+	// Every unknown or missing field is reported, so that a single pass over
+	// the input shows all that is wrong with it.
+	return errors.Join(errs...)
+}
+
+// stanzaType is the type of a Stanza, which a catch-all field may hold.
+var stanzaType = reflect.TypeOf(Stanza{})
+
+// marshalRest adds the contents of a catch-all field to a stanza, leaving out
+// those that a field of the struct described by info is named after.
+func marshalRest(stanza *Stanza, value reflect.Value, info *structInfo) error {
+	set := func(key, text string) {
+		if _, found := info.byName[strings.ToLower(key)]; !found && text != "" {
+			stanza.Set(key, text)
+		}
+	}
+
+	switch {
+	case value.Type() == stanzaType:
+		rest := value.Interface().(Stanza)
+		for _, key := range rest.Order {
+			set(key, rest.Values[key])
+		}
+	case isStringMap(value.Type()):
+		keys := make([]string, 0, value.Len())
+		for _, key := range value.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			set(key, value.MapIndex(reflect.ValueOf(key).Convert(value.Type().Key())).String())
 		}
+	default:
+		return fmt.Errorf("unsupported type for rest field: %s", value.Type())
+	}
+
+	return nil
+}
 
-		if err := unmarshalFieldText(value, text); err != nil {
-			return fmt.Errorf("failed to unmarshal field %q: %w", key, err)
+// unmarshalRest stores a stanza field in a catch-all field.
+func unmarshalRest(value reflect.Value, key, text string) error {
+	switch {
+	case value.Type() == stanzaType:
+		value.Addr().Interface().(*Stanza).put(key, text)
+	case isStringMap(value.Type()):
+		if value.IsNil() {
+			value.Set(reflect.MakeMap(value.Type()))
 		}
+
+		value.SetMapIndex(reflect.ValueOf(key).Convert(value.Type().Key()), reflect.ValueOf(text).Convert(value.Type().Elem()))
+	default:
+		return fmt.Errorf("unsupported type for rest field: %s", value.Type())
 	}
 
 	return nil
 }
 
+// isStringMap reports whether t is a map from strings to strings.
+func isStringMap(t reflect.Type) bool {
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && t.Elem().Kind() == reflect.String
+}
+
 // fieldByIndex walks an index path down to a field. It reports false when the
 // path runs through a nil pointer, in which case there is no value to render.
 func fieldByIndex(value reflect.Value, index []int) (reflect.Value, bool) {
@@ -151,13 +273,41 @@ func fieldByIndexAlloc(value reflect.Value, index []int) (reflect.Value, error)
 // marshalFieldText renders a single value as deb822 field text. The boolean
 // result reports whether there is a value to write at all; it is false for nil
 // pointers and nil interfaces.
-func marshalFieldText(value reflect.Value) (string, bool, error) {
+//
+// A codec registered with codecs for the type of the value comes first, then
+// its MarshalTextWith or MarshalText method, then the built-in handling of
+// its kind.
+func marshalFieldText(value reflect.Value, codecs *Codecs) (string, bool, error) {
 	if kind := value.Kind(); kind == reflect.Pointer || kind == reflect.Interface {
 		if value.IsNil() {
 			return "", false, nil
 		}
 	}
 
+	if marshal := codecs.marshaler(value.Type()); marshal != nil {
+		text, err := marshal(value)
+		if err != nil {
+			return "", false, err
+		}
+
+		return text, true, nil
+	}
+
+	if value.Kind() == reflect.Pointer && codecs.marshaler(value.Type().Elem()) != nil {
+		return marshalFieldText(value.Elem(), codecs)
+	}
+
+	if codecs != nil {
+		if marshaler, ok := asCodecMarshaler(value); ok {
+			text, err := marshaler.MarshalTextWith(codecs)
+			if err != nil {
+				return "", false, err
+			}
+
+			return string(text), true, nil
+		}
+	}
+
 	if marshaler, ok := asTextMarshaler(value); ok {
 		text, err := marshaler.MarshalText()
 		if err != nil {
@@ -167,9 +317,13 @@ func marshalFieldText(value reflect.Value) (string, bool, error) {
 		return string(text), true, nil
 	}
 
+	if value.Type() == durationType {
+		return time.Duration(value.Int()).String(), true, nil
+	}
+
 	switch value.Kind() {
 	case reflect.Pointer, reflect.Interface:
-		return marshalFieldText(value.Elem())
+		return marshalFieldText(value.Elem(), codecs)
 	case reflect.String:
 		return value.String(), true, nil
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -184,11 +338,20 @@ func marshalFieldText(value reflect.Value) (string, bool, error) {
 		return strconv.FormatFloat(value.Float(), 'g', -1, 64), true, nil
 	}
 
-	return "", false, fmt.Errorf("unsupported type: %s", value.Type())
+	return "", false, unsupportedType(value.Type())
 }
 
-// unmarshalFieldText parses deb822 field text into a single value.
-func unmarshalFieldText(value reflect.Value, text string) error {
+// unmarshalFieldText parses deb822 field text into a single value, in the
+// same order of precedence as marshalFieldText.
+func unmarshalFieldText(value reflect.Value, text string, codecs *Codecs) error {
+	if unmarshal := codecs.unmarshaler(value.Type()); unmarshal != nil {
+		if !value.CanSet() {
+			return fmt.Errorf("cannot set value of type %s", value.Type())
+		}
+
+		return unmarshal(value, text)
+	}
+
 	if value.Kind() == reflect.Pointer {
 		if value.IsNil() {
 			if !value.CanSet() {
@@ -198,16 +361,18 @@ func unmarshalFieldText(value reflect.Value, text string) error {
 			value.Set(reflect.New(value.Type().Elem()))
 		}
 
-		if unmarshaler, ok := value.Interface().(encoding.TextUnmarshaler); ok {
-			return unmarshaler.UnmarshalText([]byte(text))
+		if codecs.unmarshaler(value.Type().Elem()) == nil {
+			if ok, err := unmarshalTextMethods(value, text, codecs); ok {
+				return err
+			}
 		}
 
-		return unmarshalFieldText(value.Elem(), text)
+		return unmarshalFieldText(value.Elem(), text, codecs)
 	}
 
 	if value.CanAddr() {
-		if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
-			return unmarshaler.UnmarshalText([]byte(text))
+		if ok, err := unmarshalTextMethods(value.Addr(), text, codecs); ok {
+			return err
 		}
 	}
 
@@ -215,6 +380,17 @@ func unmarshalFieldText(value reflect.Value, text string) error {
 		return fmt.Errorf("cannot set value of type %s", value.Type())
 	}
 
+	if value.Type() == durationType {
+		parsed, err := parseDuration(text)
+		if err != nil {
+			return err
+		}
+
+		value.SetInt(int64(parsed))
+
+		return nil
+	}
+
 	switch value.Kind() {
 	case reflect.String:
 		value.SetString(text)
@@ -247,12 +423,53 @@ func unmarshalFieldText(value reflect.Value, text string) error {
 
 		value.SetFloat(parsed)
 	default:
-		return fmt.Errorf("unsupported type: %s", value.Type())
+		return unsupportedType(value.Type())
 	}
 
 	return nil
 }
 
+// unsupportedType reports a type that cannot be carried as field text,
+// pointing slices at the list option.
+func unsupportedType(t reflect.Type) error {
+	if t.Kind() == reflect.Slice {
+		return fmt.Errorf("unsupported type: %s (a delimited list needs the list option)", t)
+	}
+
+	return fmt.Errorf("unsupported type: %s", t)
+}
+
+// durationType is the type of a time.Duration, which is carried as text the
+// way time.Duration.String renders it rather than as a number of nanoseconds.
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// parseDuration parses a duration as time.ParseDuration does, except that a
+// bare number is taken as a number of nanoseconds, the way a time.Duration was
+// written before it was carried as text.
+func parseDuration(text string) (time.Duration, error) {
+	if nanoseconds, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return time.Duration(nanoseconds), nil
+	}
+
+	return time.ParseDuration(text)
+}
+
+// unmarshalTextMethods parses text with the UnmarshalTextWith or UnmarshalText
+// method of the value pointer points to, and reports whether it has either.
+func unmarshalTextMethods(pointer reflect.Value, text string, codecs *Codecs) (bool, error) {
+	if codecs != nil {
+		if unmarshaler, ok := pointer.Interface().(CodecUnmarshaler); ok {
+			return true, unmarshaler.UnmarshalTextWith([]byte(text), codecs)
+		}
+	}
+
+	if unmarshaler, ok := pointer.Interface().(encoding.TextUnmarshaler); ok {
+		return true, unmarshaler.UnmarshalText([]byte(text))
+	}
+
+	return false, nil
+}
+
 // isEmptyValue reports whether a value counts as empty for the omitempty
 // option: the zero value of its type, or an empty string, slice, array or map.
 func isEmptyValue(value reflect.Value) bool {
@@ -264,6 +481,22 @@ func isEmptyValue(value reflect.Value) bool {
 	return value.IsZero()
 }
 
+// asCodecMarshaler returns the CodecMarshaler of a value, preferring the value
+// itself and falling back to its address when that is available.
+func asCodecMarshaler(value reflect.Value) (CodecMarshaler, bool) {
+	if marshaler, ok := value.Interface().(CodecMarshaler); ok {
+		return marshaler, true
+	}
+
+	if value.CanAddr() {
+		marshaler, ok := value.Addr().Interface().(CodecMarshaler)
+
+		return marshaler, ok
+	}
+
+	return nil, false
+}
+
 // asTextMarshaler returns the encoding.TextMarshaler of a value, preferring
 // the value itself and falling back to its address when that is available.
 func asTextMarshaler(value reflect.Value) (encoding.TextMarshaler, bool) {
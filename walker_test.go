@@ -12,6 +12,7 @@ package deb822_test
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"oaklab.hu/debian/deb822"
@@ -277,3 +278,160 @@ func TestEmptyValuesAreDropped(t *testing.T) {
 	require.NoError(t, deb822.Unmarshal([]byte("Version:\nSize: 4\n"), &got))
 	require.Equal(t, walkerTypes{Size: 4}, got)
 }
+
+// withRest embeds a known struct and keeps everything else in a Stanza, the
+// way a consumer extends types.Package with vendor fields.
+type withRest struct {
+	inlinedInner
+	Extra deb822.Stanza `debian:",rest"`
+	Value string        `debian:"Value"`
+}
+
+// withRestMap keeps the unmatched fields in a map instead.
+type withRestMap struct {
+	Value string            `debian:"Value"`
+	Extra map[string]string `debian:",rest"`
+}
+
+func TestRestField(t *testing.T) {
+	input := `Inner-Value: inner
+X-Cargo-Built-Using: rust-foo (= 1.0)
+Value: value
+Ruby-Versions: all
+`
+
+	t.Run("stanza", func(t *testing.T) {
+		var decoded withRest
+		require.NoError(t, deb822.Unmarshal([]byte(input), &decoded, deb822.WithDisallowUnknownFields()))
+		require.Equal(t, "inner", decoded.Inner)
+		require.Equal(t, "value", decoded.Value)
+		require.Equal(t, []string{"X-Cargo-Built-Using", "Ruby-Versions"}, decoded.Extra.Order)
+		require.Equal(t, "all", decoded.Extra.Values["Ruby-Versions"])
+
+		// The unmatched fields come back in their original order, at the
+		// position of the catch-all field.
+		require.Equal(t, `Inner-Value: inner
+X-Cargo-Built-Using: rust-foo (= 1.0)
+Ruby-Versions: all
+Value: value
+`, marshalToString(t, decoded))
+	})
+
+	t.Run("map", func(t *testing.T) {
+		var decoded withRestMap
+		require.NoError(t, deb822.Unmarshal([]byte(input), &decoded))
+		require.Equal(t, map[string]string{
+			"Inner-Value":         "inner",
+			"X-Cargo-Built-Using": "rust-foo (= 1.0)",
+			"Ruby-Versions":       "all",
+		}, decoded.Extra)
+
+		// A map has no order, so its contents are sorted by name.
+		decoded.Extra["value"] = "shadowed by the Value field"
+		require.Equal(t, `Value: value
+Inner-Value: inner
+Ruby-Versions: all
+X-Cargo-Built-Using: rust-foo (= 1.0)
+`, marshalToString(t, decoded))
+	})
+}
+
+type withRequired struct {
+	Name    string `debian:"Name,required"`
+	Version string `debian:"Version,omitempty,required"`
+	Comment string `debian:"Comment,omitempty"`
+}
+
+func TestRequiredField(t *testing.T) {
+	t.Run("decode", func(t *testing.T) {
+		var decoded withRequired
+		require.NoError(t, deb822.Unmarshal([]byte("name: foo\nVersion: 1.0\n"), &decoded))
+		require.Equal(t, withRequired{Name: "foo", Version: "1.0"}, decoded)
+
+		// Every missing field is reported, an empty value counting as
+		// missing.
+		err := deb822.Unmarshal([]byte("Version:\nComment: no name\n"), &decoded)
+		require.ErrorIs(t, err, deb822.ErrMissingField)
+
+		var fields []string
+		for _, err := range err.(interface{ Unwrap() []error }).Unwrap() {
+			var parseErr *deb822.ParseError
+			require.ErrorAs(t, err, &parseErr)
+			require.Equal(t, 1, parseErr.Stanza)
+			fields = append(fields, parseErr.Field)
+		}
+		require.Equal(t, []string{"Name", "Version"}, fields)
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		require.Equal(t, "Name: foo\nVersion: 1.0\n", marshalToString(t, withRequired{Name: "foo", Version: "1.0"}))
+
+		// omitempty does not let a required field go missing.
+		var sb strings.Builder
+		err := deb822.Marshal(&sb, withRequired{Name: "foo"})
+		require.ErrorIs(t, err, deb822.ErrMissingField)
+		require.ErrorContains(t, err, `"Version"`)
+	})
+}
+
+type withCollections struct {
+	Binary    []string          `debian:"Binary,list=comma"`
+	Arches    []arch.Arch       `debian:"Architecture,list=space"`
+	Ports     []uint16          `debian:"Ports,list=line"`
+	Checksums map[string]string `debian:"Checksums"`
+	Timeout   time.Duration     `debian:"Timeout,omitempty"`
+	Delays    []time.Duration   `debian:"Delays,list=comma"`
+}
+
+func TestCollections(t *testing.T) {
+	input := `Binary: foo, foo-dev,
+Architecture: amd64  arm64
+Ports:
+ 80
+ 443
+Checksums:
+ e3b0c442 0 empty
+ a1b2c3d4 1024 data.tar.xz
+Timeout: 1m30s
+Delays: 1s, 30000000000
+`
+
+	var decoded withCollections
+	require.NoError(t, deb822.Unmarshal([]byte(input), &decoded))
+	require.Equal(t, withCollections{
+		Binary:    []string{"foo", "foo-dev"},
+		Arches:    []arch.Arch{arch.MustParse("amd64"), arch.MustParse("arm64")},
+		Ports:     []uint16{80, 443},
+		Checksums: map[string]string{"e3b0c442": "0 empty", "a1b2c3d4": "1024 data.tar.xz"},
+		Timeout:   90 * time.Second,
+		Delays:    []time.Duration{time.Second, 30 * time.Second},
+	}, decoded)
+
+	require.Equal(t, "Binary: foo, foo-dev\n"+
+		"Architecture: amd64 arm64\n"+
+		"Ports: \n 80\n 443\n"+
+		"Checksums: \n a1b2c3d4 1024 data.tar.xz\n e3b0c442 0 empty\n"+
+		"Timeout: 1m30s\n"+
+		"Delays: 1s, 30s\n", marshalToString(t, decoded))
+
+	t.Run("empty", func(t *testing.T) {
+		require.Empty(t, marshalToString(t, withCollections{Binary: []string{}, Checksums: map[string]string{}}))
+	})
+
+	t.Run("bare number of nanoseconds", func(t *testing.T) {
+		var decoded withCollections
+		require.NoError(t, deb822.Unmarshal([]byte("Timeout: 90000000000\n"), &decoded))
+		require.Equal(t, 90*time.Second, decoded.Timeout)
+
+		err := deb822.Unmarshal([]byte("Timeout: 9223372036854775808\n"), &decoded)
+		require.Error(t, err)
+	})
+
+	t.Run("without list option", func(t *testing.T) {
+		var decoded struct {
+			Binary []string `debian:"Binary"`
+		}
+		err := deb822.Unmarshal([]byte("Binary: foo\n"), &decoded)
+		require.ErrorContains(t, err, "list option")
+	})
+}